@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+	"go-logshield/internal/normalizer"
+	"go-logshield/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- -demo: 입력 없이 화면만 확인할 때 쓰는 가짜 경고 생성 ---
+
+// demoMaxAlerts stops the generator so the list stays readable.
+const demoMaxAlerts = 50
+
+// demoRules are the fake rules. Their IDs and "(데모)" titles are never
+// used by a real detector, so a list or report.json made in -demo mode
+// cannot pass for real detections.
+var demoRules = []struct {
+	id   string
+	sev  detector.Severity
+	info format.RuleInfo
+}{
+	{"DEMO_WEB_ENUMERATION", detector.SeverityMedium, format.RuleInfo{
+		Title:       "웹 경로 스캐닝 의심(데모)",
+		Description: "민감 경로에 대한 접근이 반복되었습니다. (데모 모드에서 만든 가짜 경고)",
+		KeyLabel:    "IP",
+		CountLabel:  "시도 횟수",
+	}},
+	{"DEMO_BRUTE_FORCE_LOGIN", detector.SeverityHigh, format.RuleInfo{
+		Title:       "로그인 브루트포스 의심(데모)",
+		Description: "동일 IP에서 로그인 실패가 짧은 시간에 반복되었습니다. (데모 모드에서 만든 가짜 경고)",
+		KeyLabel:    "IP",
+		CountLabel:  "실패 횟수",
+	}},
+	{"DEMO_SSH_BRUTE_FORCE", detector.SeverityHigh, format.RuleInfo{
+		Title:       "SSH 브루트포스 의심(데모)",
+		Description: "동일 IP에서 SSH 인증 실패가 짧은 시간에 반복되었습니다. (데모 모드에서 만든 가짜 경고)",
+		KeyLabel:    "IP",
+		CountLabel:  "실패 횟수",
+	}},
+}
+
+// runDemo sends a fake alert every 300ms until demoMaxAlerts or ctx is
+// done. Alerts sent while paused wait in the dashboard's queue.
+func runDemo(ctx context.Context, p *tea.Program) {
+	for _, r := range demoRules {
+		format.Register(r.id, r.info)
+	}
+
+	t := time.NewTicker(300 * time.Millisecond)
+	defer t.Stop()
+	for n := 0; n < demoMaxAlerts; n++ {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			p.Send(tui.AlertMsg{Alert: demoAlert(now)})
+		}
+	}
+	p.Send(tui.StatusMsg("데모 경고를 모두 생성했습니다 (q 종료)"))
+}
+
+// demoAlert makes one fake alert, picking the rule from the clock.
+func demoAlert(now time.Time) detector.Alert {
+	// 3개 중 하나를 랜덤처럼 바꾸기(간단히 시간으로)
+	r := demoRules[now.UnixNano()%int64(len(demoRules))]
+
+	return detector.Alert{
+		RuleID:    r.id,
+		Severity:  r.sev,
+		Key:       "198.51.100.23",
+		Count:     1,
+		FirstSeen: now,
+		LastSeen:  now,
+		Events: []normalizer.Event{
+			{TS: now, Service: "demo", IP: "198.51.100.23"},
+		},
+	}
+}
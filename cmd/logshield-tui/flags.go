@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-logshield/internal/config"
+)
+
+// defaultConfigFile is read when -config is not given and the file exists.
+const defaultConfigFile = "logshield.yaml"
+
+// options are the settings of one logshield-tui run.
+type options struct {
+	cfg config.Config
+
+	// demo shows generated alerts instead of running the pipeline.
+	demo bool
+	// follow keeps reading files as they grow (tail -F); otherwise the
+	// inputs are read once.
+	follow bool
+}
+
+// listFlag collects a repeatable flag; each value may be comma-separated.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
+
+func parseFlags(args []string) (options, error) {
+	fs := flag.NewFlagSet("logshield-tui", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: logshield-tui [flags] [input ...]\n\n")
+		fmt.Fprintf(fs.Output(), "inputs are files, globs or directories (- for stdin); default ./logs/*.log\n\n")
+		fs.PrintDefaults()
+	}
+
+	var (
+		configPath = fs.String("config", "", "config file (default ./"+defaultConfigFile+" if present)")
+		rulesDir   = fs.String("rules", "", "extra rule directory (default ./rules)")
+		workers    = fs.Int("workers", 0, "detection goroutines, sharded by IP/user (default 1)")
+		follow     = fs.Bool("follow", true, "follow files as they grow (false: read them once)")
+		demo       = fs.Bool("demo", false, "show generated demo alerts instead of reading inputs")
+
+		inputs listFlag
+	)
+	fs.Var(&inputs, "input", "input file, glob or directory (repeatable)")
+
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	opts := options{cfg: config.Default(), demo: *demo, follow: *follow}
+	path := *configPath
+	if path == "" {
+		if _, err := os.Stat(defaultConfigFile); err == nil {
+			path = defaultConfigFile
+		}
+	}
+	if path != "" {
+		var err error
+		if opts.cfg, err = config.Load(path); err != nil {
+			return opts, err
+		}
+	}
+
+	// 명령행 플래그가 설정 파일보다 우선
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	inputs = append(inputs, fs.Args()...)
+	if len(inputs) > 0 {
+		opts.cfg.Inputs = inputs
+	}
+	if set["rules"] {
+		opts.cfg.RulesDir = *rulesDir
+	}
+	if set["workers"] {
+		opts.cfg.Workers = *workers
+	}
+	return opts, opts.cfg.Validate()
+}
@@ -1,302 +1,157 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
-	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
-)
-
-type Alert struct {
-	TS       time.Time `json:"ts"`
-	Severity string    `json:"severity"` // "낮음/중간/높음/치명"
-	Title    string    `json:"title"`
-	Message  string    `json:"message"`
-
-	// 확장용(나중에 detector에서 채울 수 있음)
-	IP      string `json:"ip,omitempty"`
-	RuleID  string `json:"rule_id,omitempty"`
-	Service string `json:"service,omitempty"`
-}
+	"go-logshield/internal/config"
+	"go-logshield/internal/detector"
+	"go-logshield/internal/ingest"
+	"go-logshield/internal/normalizer"
+	"go-logshield/internal/pipeline"
+	"go-logshield/internal/tui"
 
-type viewMode int
-
-const (
-	viewList viewMode = iota
-	viewDetail
+	tea "github.com/charmbracelet/bubbletea"
 )
 
-type model struct {
-	paused   bool
-	showHelp bool
-	mode     viewMode
-
-	alerts   []Alert
-	selected int // alerts index
-
-	statusLine string // 저장 완료/에러 같은 상태 메시지
-}
-
-func initialModel() model {
-	return model{
-		paused:     false,
-		showHelp:   true,
-		mode:       viewList,
-		alerts:     make([]Alert, 0, 50),
-		selected:   0,
-		statusLine: "",
+// --- 입력 → 파이프라인 → TUI ---
+// 소스 고루틴들(tail/read) → 채널 → 탐지 단계 → p.Send(...)로 화면 갱신
+// 입력이 끝나거나(-follow=false, stdin) ctx가 취소될 때까지 블록
+func runPipeline(ctx context.Context, p *tea.Program, opts options) error {
+	cfg := opts.cfg
+	files, err := cfg.ExpandInputs()
+	if err != nil {
+		return err
 	}
-}
-
-// --- tick (데모용 이벤트 생성) ---
-type tickMsg time.Time
-
-func tick() tea.Cmd {
-	return tea.Tick(300*time.Millisecond, func(t time.Time) tea.Msg {
-		return tickMsg(t)
-	})
-}
-
-// --- 저장 결과 메시지 ---
-type savedMsg struct{ path string }
-type errMsg struct{ err error }
-
-func saveReportCmd(alerts []Alert) tea.Cmd {
-	// alerts를 복사해서 클로저에서 안전하게 사용
-	snapshot := make([]Alert, len(alerts))
-	copy(snapshot, alerts)
-
-	return func() tea.Msg {
-		b, err := json.MarshalIndent(snapshot, "", "  ")
-		if err != nil {
-			return errMsg{err: err}
-		}
-		path := "report.json"
-		if err := os.WriteFile(path, b, 0644); err != nil {
-			return errMsg{err: err}
-		}
-		return savedMsg{path: path}
+	if len(files) == 0 {
+		// 입력이 없어도 화면은 띄우고, 상태 라인으로 안내만 함
+		return fmt.Errorf("입력 파일을 찾지 못했습니다: %v (파일/디렉터리를 지정하거나 --demo)", cfg.Inputs)
 	}
-}
-
-func (m model) Init() tea.Cmd {
-	return tick()
-}
 
-func clamp(v, lo, hi int) int {
-	if v < lo {
-		return lo
+	registry, err := cfg.Registry()
+	if err != nil {
+		return err
 	}
-	if v > hi {
-		return hi
-	}
-	return v
-}
-
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch x := msg.(type) {
-
-	case tea.KeyMsg:
-		k := x.String()
-
-		// --- 글로벌 단축키 ---
-		switch k {
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case "h", "?":
-			m.showHelp = !m.showHelp
-			return m, nil
-		case "p":
-			m.paused = !m.paused
-			if m.paused {
-				m.statusLine = "⏸ 일시정지됨 (p로 재개)"
-			} else {
-				m.statusLine = "▶ 분석 재개됨"
-			}
-			return m, nil
-		case "c":
-			m.alerts = nil
-			m.selected = 0
-			m.mode = viewList
-			m.statusLine = "🧹 경고 목록 초기화"
-			return m, nil
-		case "s":
-			// 현재까지 경고를 report.json으로 저장
-			if len(m.alerts) == 0 {
-				m.statusLine = "저장할 경고가 없습니다."
-				return m, nil
-			}
-			m.statusLine = "💾 report.json 저장 중..."
-			return m, saveReportCmd(m.alerts)
-		case "esc":
-			// 상세보기에서 리스트로 돌아가기
-			if m.mode == viewDetail {
-				m.mode = viewList
-				m.statusLine = "리스트로 돌아옴"
-			}
-			return m, nil
-		case "enter":
-			// 선택된 항목 상세 보기 토글
-			if len(m.alerts) == 0 {
-				return m, nil
-			}
-			if m.mode == viewList {
-				m.mode = viewDetail
-			} else {
-				m.mode = viewList
+	p.Send(tui.StatusMsg(fmt.Sprintf("분석 시작: %d개 입력 (follow=%v)", len(files), opts.follow)))
+
+	if opts.follow {
+		// 파일은 기존 내용을 타임스탬프 순으로 병합해 읽은 뒤 tail -F 로 계속
+		// 따라가고, stdin은 끝날 때까지 읽음
+		err = analyze(ctx, p, cfg, registry, func(pl *pipeline.Pipeline) {
+			var paths []string
+			for _, file := range files {
+				if file == normalizer.StdinSource {
+					pl.Read(ctx, file, os.Stdin)
+				} else {
+					paths = append(paths, file)
+				}
 			}
-			return m, nil
+			pl.TailAll(ctx, paths, cfg.MergeBuffer)
+		})
+		if err != nil {
+			return err
 		}
+		p.Send(tui.DoneMsg{})
+		return nil
+	}
 
-		// --- 리스트 탐색 단축키(리스트 모드에서만) ---
-		if m.mode == viewList && len(m.alerts) > 0 {
-			switch k {
-			case "up", "k":
-				m.selected = clamp(m.selected-1, 0, len(m.alerts)-1)
-				return m, nil
-			case "down", "j":
-				m.selected = clamp(m.selected+1, 0, len(m.alerts)-1)
-				return m, nil
-			case "g":
-				m.selected = 0
-				return m, nil
-			case "G":
-				m.selected = len(m.alerts) - 1
-				return m, nil
+	streams := make([]ingest.Stream, 0, len(files))
+	for _, file := range files {
+		fp := os.Stdin
+		if file != normalizer.StdinSource {
+			if fp, err = os.Open(file); err != nil {
+				return err
 			}
+			defer fp.Close()
 		}
-
-	case tickMsg:
-		// 데모: paused 아니면 가짜 경고를 주기적으로 추가
-		// 다음 스텝에서 여기만 "진짜 Detector -> Alert 채널"로 교체하면 됨.
-		if !m.paused {
-			if len(m.alerts) < 50 {
-				sev := "중간"
-				title := "웹 경로 스캐닝 의심(데모)"
-				msg := "민감 경로에 대한 접근이 반복되었습니다."
-
-				// 3개 중 하나를 랜덤처럼 바꾸기(간단히 시간으로)
-				n := int(time.Now().UnixNano() % 3)
-				if n == 0 {
-					sev = "높음"
-					title = "로그인 브루트포스 의심(데모)"
-					msg = "동일 IP에서 로그인 실패가 짧은 시간에 반복되었습니다."
-				} else if n == 1 {
-					sev = "높음"
-					title = "SSH 브루트포스 의심(데모)"
-					msg = "동일 IP에서 SSH 인증 실패가 짧은 시간에 반복되었습니다."
-				}
-
-				m.alerts = append(m.alerts, Alert{
-					TS:       time.Now(),
-					Severity: sev,
-					Title:    title,
-					Message:  msg,
-					IP:       "198.51.100.23",
-					RuleID:   "DEMO_RULE",
-					Service:  "demo",
-				})
-
-				// 새 경고가 들어오면 커서가 범위를 벗어나지 않게
-				m.selected = clamp(m.selected, 0, len(m.alerts)-1)
+		streams = append(streams, ingest.NewLineStream(fp, registry.Source(file)))
+	}
+	if cfg.Merge {
+		// 한 번만 읽을 때는 logshield와 같이 타임스탬프 순으로 병합
+		err = analyze(ctx, p, cfg, registry, func(pl *pipeline.Pipeline) {
+			pl.Feed(ctx, ingest.NewMerger(cfg.MergeBuffer, streams...))
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		// 순차 모드: logshield -merge=false 와 같이 파일마다 탐지 단계를 새로 만듦
+		for _, st := range streams {
+			err = analyze(ctx, p, cfg, registry, func(pl *pipeline.Pipeline) {
+				pl.Feed(ctx, ingest.NewMerger(cfg.MergeBuffer, st))
+			})
+			if err != nil {
+				return err
 			}
 		}
-		return m, tick()
-
-	case savedMsg:
-		m.statusLine = fmt.Sprintf("✅ 저장 완료: %s", x.path)
-		return m, nil
+	}
+	p.Send(tui.DoneMsg{})
+	return nil
+}
 
-	case errMsg:
-		m.statusLine = fmt.Sprintf("❌ 오류: %v", x.err)
-		return m, nil
+// analyze builds a pipeline and its detection stage, lets feed add the
+// sources and runs until they end or ctx is done.
+func analyze(ctx context.Context, p *tea.Program, cfg config.Config, registry *normalizer.Registry, feed func(pl *pipeline.Pipeline)) error {
+	pl := pipeline.New(registry, pipeline.Options{
+		OnEvent: func(ev normalizer.Event) { p.Send(tui.EventMsg{Event: ev}) },
+		OnAlert: func(a detector.Alert) { p.Send(tui.AlertMsg{Alert: a}) },
+		OnError: func(source string, err error) { p.Send(tui.ParseErrMsg{Source: source, Err: err}) },
+	})
+	stage, err := cfg.Stage(pl.Sink)
+	if err != nil {
+		return err
 	}
+	p.Send(tui.StageMsg{Stats: stage.Stats, Late: stage.Late})
 
-	return m, nil
+	feed(pl)
+	return pl.Run(ctx, stage)
 }
 
-func (m model) View() string {
-	header := "Go-LogShield TUI\n"
-	state := "RUNNING"
-	if m.paused {
-		state = "PAUSED"
-	}
-	header += fmt.Sprintf("상태: %s | 경고 수: %d | 모드: %s\n",
-		state, len(m.alerts), map[viewMode]string{viewList: "LIST", viewDetail: "DETAIL"}[m.mode],
-	)
-	if m.statusLine != "" {
-		header += m.statusLine + "\n"
+func main() {
+	opts, err := parseFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
-	header += "--------------------------------------------------\n"
-
-	help := ""
-	if m.showHelp {
-		help += "단축키\n"
-		help += "  q: 종료   p: 일시정지/재개   c: 초기화   s: report.json 저장\n"
-		help += "  h/?: 도움말 토글   ↑/k ↓/j: 이동   enter: 상세보기 토글   esc: 리스트로\n"
-		help += "  g: 맨위   G: 맨아래\n"
-		help += "--------------------------------------------------\n"
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "logshield-tui:", err)
+		os.Exit(2)
 	}
 
-	if len(m.alerts) == 0 {
-		return header + help + "(아직 경고 없음)\n"
+	// stdin으로 로그를 받아도 키 입력은 bubbletea가 TTY에서 따로 읽음
+	title, status := "Go-LogShield TUI", "로그 분석 시작됨 (q 종료, p 일시정지)"
+	if opts.demo {
+		title, status = "Go-LogShield TUI (데모)", "데모 모드: 가짜 경고를 생성합니다"
 	}
-
-	// 리스트 모드
-	if m.mode == viewList {
-		out := header + help
-		out += "최근 경고 목록 (enter로 상세보기)\n\n"
-
-		// 최신이 아래로 쌓이는 대신, 화면에서는 “위에서 아래로” 보기 편하게 최근순 역순 출력
-		// 하지만 selected는 실제 slice index 기준이므로, 출력 시 매핑해줌.
-		// 출력 i번째 항목 -> 실제 index = len(alerts)-1-i
-		for i := 0; i < len(m.alerts); i++ {
-			idx := len(m.alerts) - 1 - i
-			a := m.alerts[idx]
-
-			cursor := "  "
-			if idx == m.selected {
-				cursor = "> "
-			}
-
-			out += fmt.Sprintf("%s[%s] %s  (%s)\n",
-				cursor,
-				a.Severity,
-				a.Title,
-				a.TS.Format("15:04:05"),
-			)
+	m := tui.New(tui.Options{
+		Title:  title,
+		Status: status,
+		Styles: tui.NewStyles(tui.DetectColor()),
+	})
+	p := tea.NewProgram(m, tea.WithAltScreen())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if opts.demo {
+			runDemo(ctx, p)
+			return
 		}
-		out += "\n"
-		return out
-	}
+		if err := runPipeline(ctx, p, opts); err != nil && !errors.Is(err, context.Canceled) {
+			p.Send(tui.ErrMsg{Err: err})
+		}
+	}()
 
-	// 상세 모드
-	a := m.alerts[m.selected]
-	out := header + help
-	out += "상세 보기 (esc 또는 enter로 돌아가기)\n\n"
-	out += fmt.Sprintf("🚨 제목: %s\n", a.Title)
-	out += fmt.Sprintf("등급: %s\n", a.Severity)
-	out += fmt.Sprintf("시간: %s\n", a.TS.Format(time.RFC3339))
-	if a.IP != "" {
-		out += fmt.Sprintf("IP: %s\n", a.IP)
-	}
-	if a.Service != "" {
-		out += fmt.Sprintf("서비스: %s\n", a.Service)
-	}
-	if a.RuleID != "" {
-		out += fmt.Sprintf("RuleID: %s\n", a.RuleID)
-	}
-	out += "\n설명\n"
-	out += fmt.Sprintf("  %s\n", a.Message)
-	out += "\n"
-	return out
-}
+	_, err = p.Run()
 
-func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	// TUI 종료 → 소스/탐지 고루틴 정리
+	cancel()
+	<-done
+
+	if err != nil {
 		panic(err)
 	}
 }
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-logshield/internal/config"
+	"go-logshield/internal/output"
+	"go-logshield/internal/rules"
+)
+
+// defaultConfigFile is read when -config is not given and the file exists.
+const defaultConfigFile = "logshield.yaml"
+
+// listFlag collects a repeatable flag; each value may be comma-separated.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*l = append(*l, s)
+		}
+	}
+	return nil
+}
+
+// loadConfig parses the flags and returns the config file (or defaults)
+// with the flags given on the command line applied on top.
+func loadConfig(args []string) (config.Config, error) {
+	fs := flag.NewFlagSet("logshield", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: logshield [flags] [input ...]\n\n")
+		fmt.Fprintf(fs.Output(), "inputs are files, globs or directories (- for stdin); default ./logs/*.log\n\n")
+		fs.PrintDefaults()
+	}
+
+	var (
+		configPath = fs.String("config", "", "config file (default ./"+defaultConfigFile+" if present)")
+		rulesDir   = fs.String("rules", "", "extra rule directory (default ./rules)")
+		merge      = fs.Bool("merge", true, "merge inputs in timestamp order (false: one file after another, each analyzed on its own)")
+		mergeBuf   = fs.Int("merge-buffer", 0, "per-input reorder buffer in lines (default 64)")
+		lateness   = fs.Duration("lateness", 0, "allowed event lateness behind the newest event (default 30s)")
+		cooldown   = fs.Duration("cooldown", 0, "merge repeat alerts per key until quiet this long, 0 = off (default 5m)")
+		summary    = fs.Bool("summary", true, "emit a summary alert when a suppressed incident ends")
+		workers    = fs.Int("workers", 0, "detection goroutines, sharded by IP/user (default 1)")
+		outFormat  = fs.String("output", "", "output format: "+strings.Join(output.Formats, ", ")+" (default text)")
+		events     = fs.Bool("events", false, "also write normalized events (json: each alert's events)")
+		verbosity  = fs.Int("v", 0, "verbosity: 0 alerts only, 1 + parse errors/stats, 2 + every event (default 1)")
+
+		inputs, enable, disable, windows, thresholds listFlag
+	)
+	fs.Var(&inputs, "input", "input file, glob or directory (repeatable)")
+	fs.Var(&enable, "enable", "enable detector by rule ID (repeatable)")
+	fs.Var(&disable, "disable", "disable detector by rule ID (repeatable)")
+	fs.Var(&windows, "window", "detector window, RULE_ID=30s (repeatable)")
+	fs.Var(&thresholds, "threshold", "detector threshold, RULE_ID=5 (repeatable)")
+
+	if err := fs.Parse(args); err != nil {
+		return config.Config{}, err
+	}
+
+	cfg := config.Default()
+	path := *configPath
+	if path == "" {
+		if _, err := os.Stat(defaultConfigFile); err == nil {
+			path = defaultConfigFile
+		}
+	}
+	if path != "" {
+		var err error
+		if cfg, err = config.Load(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	// 명령행 플래그가 설정 파일보다 우선
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	inputs = append(inputs, fs.Args()...)
+	if len(inputs) > 0 {
+		cfg.Inputs = inputs
+	}
+	if set["merge"] {
+		cfg.Merge = *merge
+	}
+	if set["merge-buffer"] {
+		cfg.MergeBuffer = *mergeBuf
+	}
+	if set["lateness"] {
+		cfg.Lateness = rules.Duration(*lateness)
+	}
+	if set["cooldown"] {
+		cfg.Cooldown = rules.Duration(*cooldown)
+	}
+	if set["summary"] {
+		cfg.Summary = *summary
+	}
+	if set["workers"] {
+		cfg.Workers = *workers
+	}
+	if set["rules"] {
+		cfg.RulesDir = *rulesDir
+	}
+	if set["output"] {
+		cfg.Output = *outFormat
+	}
+	if set["events"] {
+		cfg.Events = *events
+	}
+	if set["v"] {
+		cfg.Verbosity = *verbosity
+	}
+
+	for _, id := range enable {
+		d := cfg.Detector(id)
+		d.Enabled = ptr(true)
+		cfg.SetDetector(id, d)
+	}
+	for _, id := range disable {
+		d := cfg.Detector(id)
+		d.Enabled = ptr(false)
+		cfg.SetDetector(id, d)
+	}
+	for _, kv := range windows {
+		id, v, err := splitRuleValue(kv)
+		if err != nil {
+			return cfg, fmt.Errorf("-window: %w", err)
+		}
+		w, err := time.ParseDuration(v)
+		if err != nil || w <= 0 {
+			return cfg, fmt.Errorf("-window %s: bad duration %q", id, v)
+		}
+		d := cfg.Detector(id)
+		d.Window = rules.Duration(w)
+		cfg.SetDetector(id, d)
+	}
+	for _, kv := range thresholds {
+		id, v, err := splitRuleValue(kv)
+		if err != nil {
+			return cfg, fmt.Errorf("-threshold: %w", err)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return cfg, fmt.Errorf("-threshold %s: bad number %q", id, v)
+		}
+		d := cfg.Detector(id)
+		d.Threshold = n
+		cfg.SetDetector(id, d)
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func splitRuleValue(s string) (id, value string, err error) {
+	id, value, ok := strings.Cut(s, "=")
+	if !ok || id == "" || value == "" {
+		return "", "", errors.New("want RULE_ID=value, got " + strconv.Quote(s))
+	}
+	return id, value, nil
+}
+
+func ptr[T any](v T) *T { return &v }
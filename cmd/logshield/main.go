@@ -1,95 +1,207 @@
 package main
 
 import (
-	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"sync"
 	"time"
 
+	"go-logshield/internal/config"
 	"go-logshield/internal/detector"
+	"go-logshield/internal/ingest"
 	"go-logshield/internal/normalizer"
+	"go-logshield/internal/output"
+	"go-logshield/internal/rules"
 )
 
 func main() {
-	// 1) 로그 파일 찾기
-	files, err := filepath.Glob("./logs/*.log")
+	// 1) 설정 로드 (설정 파일 + 명령행 플래그)
+	cfg, err := loadConfig(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	files, err := cfg.ExpandInputs()
 	if err != nil {
 		log.Fatal(err)
 	}
 	if len(files) == 0 {
-		log.Fatal("no log files found in ./logs/")
+		log.Fatalf("no log files found in %q", cfg.Inputs)
 	}
 
-	// 2) Detector 초기화
-	bruteForceDetector := detector.NewBruteForceDetector(detector.BruteForceConfig{
-		Window:    20 * time.Second,
-		Threshold: 5,
-	})
+	registry, err := cfg.Registry()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	sshBruteForceDetector := detector.NewSSHBruteForceDetector(
-		30*time.Second,
-		6,
-	)
+	// 2) 출력 준비: 경고는 stdout, 진단 메시지는 text 형식일 때만 stdout (그 외 stderr)
+	out, err := output.New(cfg.Output, os.Stdout, output.Options{Events: cfg.Events})
+	if err != nil {
+		log.Fatal(err)
+	}
+	diag := os.Stderr
+	if cfg.Output == output.Text {
+		diag = os.Stdout
+	}
 
-	webEnumDetector := detector.NewWebEnumDetector(
-		30*time.Second,
-		4,
+	// -workers 2 이상이면 sink는 수집 고루틴에서 돌기 때문에 out/diag 쓰기를
+	// mu로 묶고, 출력 오류는 outErr에 남겨 아래 루프에서 종료
+	var (
+		mu     sync.Mutex
+		outErr error
 	)
+	diagf := func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(diag, format, args...)
+	}
+	failed := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return outErr
+	}
 
-	// 3) 로그 파일 순회
-	for _, file := range files {
-		fmt.Println("===", file, "===")
-
-		fp, err := os.Open(file)
-		if err != nil {
-			log.Fatal(err)
+	// 3) 룰 로드 (기본 룰 + 룰 디렉터리 + 탐지기별 설정)
+	// 탐지 결과는 입력 순서대로 sink로 전달됨 (-workers 로 샤딩해도 동일)
+	sink := func(ev normalizer.Event, alerts []detector.Alert) {
+		mu.Lock()
+		defer mu.Unlock()
+		if outErr != nil {
+			return
 		}
 
-		scanner := bufio.NewScanner(fp)
-		for scanner.Scan() {
-			line := scanner.Text()
+		// 빈 이벤트 = 입력 끝에서 flush된 종료 요약만 전달
+		if !ev.TS.IsZero() {
+			// 디버그용 이벤트 출력 (-v 2)
+			if cfg.Verbosity >= 2 {
+				fmt.Fprintln(diag, output.EventLine(ev))
+			}
+			if outErr = out.Event(ev); outErr != nil {
+				return
+			}
+		}
 
-			// 4) 로그 → Event 정규화
-			ev, err := normalizer.ParseLine(line)
-			if err != nil {
-				fmt.Println("PARSE_ERR:", err, "line:", line)
-				continue
+		// 탐지 → 경고 출력
+		for _, a := range alerts {
+			if outErr = out.Alert(a); outErr != nil {
+				return
 			}
+		}
+	}
+	stage, err := cfg.Stage(sink)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-			// (선택) 디버그용 이벤트 출력
-			fmt.Printf(
-				"%s service=%s action=%s user=%s ip=%s status=%s path=%s\n",
-				ev.TS.Format("15:04:05"),
-				ev.Service,
-				ev.Action,
-				ev.User,
-				ev.IP,
-				ev.Status,
-				ev.Path,
-			)
-
-			// 5) 로그인 브루트포스 탐지
-			if msg, ok := bruteForceDetector.Process(ev); ok {
-				fmt.Println(msg)
+	// 4) 입력 열기: 기본은 모든 파일을 타임스탬프 순으로 병합
+	streams := make([]ingest.Stream, 0, len(files))
+	for _, file := range files {
+		fp := os.Stdin
+		if file != normalizer.StdinSource {
+			if fp, err = os.Open(file); err != nil {
+				log.Fatal(err)
 			}
+			defer fp.Close()
+		}
+		streams = append(streams, ingest.NewLineStream(fp, registry.Source(file)))
+	}
+	var stream ingest.Stream
+	if cfg.Merge {
+		stream = ingest.NewMerger(cfg.MergeBuffer, streams...)
+	} else {
+		// 파일 순서대로, 파일 안에서만 merge_buffer 범위로 재정렬
+		for i, st := range streams {
+			streams[i] = ingest.NewMerger(cfg.MergeBuffer, st)
+		}
+		stream = ingest.Concat(streams...)
+	}
 
-			// 6) SSH 브루트포스 탐지
-			if msg, ok := sshBruteForceDetector.Process(ev); ok {
-				fmt.Println(msg)
+	current := ""
+	for {
+		if err := failed(); err != nil {
+			log.Fatal(err)
+		}
+		it, ok := stream.Next()
+		if !ok {
+			break
+		}
+		if !cfg.Merge && it.Source != current {
+			// 순차 모드: 파일마다 이벤트 시간이 처음부터 다시 시작하므로
+			// 워터마크가 이전 파일을 기준으로 남지 않게 탐지 단계를 새로 만듦
+			if current != "" {
+				stage.Close()
+				report(cfg, diag, stage)
+				if stage, err = cfg.Stage(sink); err != nil {
+					log.Fatal(err)
+				}
 			}
+			current = it.Source
+			if cfg.Verbosity >= 1 {
+				diagf("=== %s ===\n", current)
+			}
+		}
 
-			// 7) 웹 경로 스캐닝 탐지
-			if msg, ok := webEnumDetector.Process(ev); ok {
-				fmt.Println(msg)
+		// 5) 로그 → Event 정규화 (파일별로 형식 자동 감지)
+		var readErr *ingest.ReadError
+		if errors.As(it.Err, &readErr) {
+			diagf("SCAN_ERR: %v\n", readErr)
+			continue
+		}
+		if it.Err != nil {
+			if cfg.Verbosity >= 1 {
+				diagf("PARSE_ERR: %v line: %s\n", it.Err, it.Line)
 			}
+			continue
 		}
+		ev := it.Event
+		if len(ev.Unparsed) > 0 && cfg.Verbosity >= 1 {
+			diagf("PARSE_WARN: unparsed tokens %q line: %s\n", ev.Unparsed, it.Line)
+		}
+		stage.Submit(ev)
+	}
+	stage.Close()
+
+	if err := failed(); err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
 
-		if err := scanner.Err(); err != nil {
-			fmt.Println("SCAN_ERR:", err)
+	// 6) 소스별 파싱 통계
+	report(cfg, diag, stage)
+	if cfg.Verbosity < 1 {
+		return
+	}
+	for _, st := range registry.Stats() {
+		parser := st.Parser
+		if parser == "" {
+			parser = normalizer.ParserAuto // 줄 수가 적어 감지가 끝나지 않음
 		}
+		fmt.Fprintf(diag, "PARSE_STATS: source=%s parser=%s lines=%d errors=%d\n",
+			st.Source, parser, st.Lines, st.Errors)
+	}
+}
 
-		_ = fp.Close()
+// report prints the late-event count and the detector state of a
+// closed stage (-v 1).
+func report(cfg config.Config, diag io.Writer, stage rules.Stage) {
+	if cfg.Verbosity < 1 {
+		return
+	}
+	if late := stage.Late(); late > 0 {
+		fmt.Fprintf(diag, "LATE_EVENTS: dropped=%d watermark=%s\n",
+			late, stage.Watermark().Format(time.RFC3339))
+	}
+	for _, st := range stage.Stats() {
+		fmt.Fprintf(diag, "STATE: rule=%s keys=%d events=%d idle_evicted=%d limit_evicted=%d\n",
+			st.RuleID, st.Keys, st.Events, st.Idle, st.Evicted)
 	}
 }
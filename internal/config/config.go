@@ -0,0 +1,472 @@
+// Package config holds the logshield settings (config file + flags) and
+// builds the parser registry and detection engine from them.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+	"go-logshield/internal/ingest"
+	"go-logshield/internal/normalizer"
+	"go-logshield/internal/output"
+	"go-logshield/internal/rules"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the logshield.yaml file. Zero fields keep their defaults.
+type Config struct {
+	// Inputs are files, globs or directories ("-" is stdin).
+	Inputs []string `yaml:"inputs"`
+	// Merge reads all inputs at once in timestamp order (k-way merge);
+	// otherwise they are analyzed one after another, each with a fresh
+	// detection stage. MergeBuffer is the per-input reorder buffer in
+	// lines, used in both modes.
+	Merge       bool `yaml:"merge"`
+	MergeBuffer int  `yaml:"merge_buffer"`
+	// Lateness is how far an event may trail the newest one seen and
+	// still be processed; older events are dropped and counted.
+	Lateness rules.Duration `yaml:"allowed_lateness"`
+	// MaxKeys and Eviction bound the keys each detector tracks (default
+	// for every detector; see DetectorConfig to override one).
+	MaxKeys  int    `yaml:"max_keys"`
+	Eviction string `yaml:"eviction"` // lru, lowest_count
+	// Cooldown merges repeat alerts for the same key into one incident
+	// until the key has been quiet that long (0 = alert on every window);
+	// Summary emits a closing alert for incidents with merged repeats.
+	Cooldown rules.Duration `yaml:"cooldown"`
+	Summary  bool           `yaml:"summary"`
+
+	// Workers > 1 shards detection by entity key over that many goroutines;
+	// the alerts, summaries included, and their order match Workers 1.
+	Workers int `yaml:"workers"`
+	// RulesDir holds extra/override rule files (see internal/rules).
+	RulesDir string `yaml:"rules_dir"`
+
+	Parsers  []ParserConfig    `yaml:"parsers"`
+	Bindings []BindingConfig   `yaml:"bindings"`
+	Programs map[string]string `yaml:"programs"` // syslog program -> parser name
+
+	// Detectors is keyed by rule ID (BRUTE_FORCE_LOGIN, PASSWORD_SPRAY, ...).
+	Detectors map[string]DetectorConfig `yaml:"detectors"`
+
+	Output    string `yaml:"output"`    // text, ndjson, json, csv (see internal/output)
+	Events    bool   `yaml:"events"`    // also write normalized events
+	Verbosity int    `yaml:"verbosity"` // 0: alerts, 1: + parse errors/stats, 2: + every event
+}
+
+// ParserConfig defines a named parser on top of the built-ins.
+type ParserConfig struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"` // "access" or "json"
+
+	LogFormat string                  `yaml:"log_format"` // access: nginx log_format
+	Mapping   *normalizer.JSONMapping `yaml:"mapping"`    // json: inline mapping
+}
+
+// BindingConfig routes sources matching Source (a glob, or "-") to Parser.
+type BindingConfig struct {
+	Source string `yaml:"source"`
+	Parser string `yaml:"parser"`
+}
+
+// DetectorConfig overrides one detector. Enabled, Window, Threshold,
+// MaxKeys, Eviction and Cooldown apply to every detector and take
+// priority over the same settings in a rule file; the built-in Go
+// detectors also read their own fields (Threshold maps to their main
+// count, see Engine).
+type DetectorConfig struct {
+	Enabled   *bool          `yaml:"enabled"`
+	Window    rules.Duration `yaml:"window"`
+	Threshold int            `yaml:"threshold"`
+
+	MaxKeys  int    `yaml:"max_keys"`
+	Eviction string `yaml:"eviction"`
+	// Cooldown overrides the global one; "0s" turns suppression off.
+	Cooldown *rules.Duration `yaml:"cooldown"`
+
+	MinFailures   int `yaml:"min_failures"`   // ACCOUNT_TAKEOVER, DISTRIBUTED_BRUTE_FORCE
+	DistinctUsers int `yaml:"distinct_users"` // PASSWORD_SPRAY
+	DistinctIPs   int `yaml:"distinct_ips"`   // DISTRIBUTED_BRUTE_FORCE
+}
+
+func (d DetectorConfig) enabled() bool { return d.Enabled == nil || *d.Enabled }
+
+// DefaultMaxKeys caps the keys (IPs, users) each detector tracks.
+const DefaultMaxKeys = 100000
+
+// DefaultLateness tolerates small clock skew and buffering between sources.
+const DefaultLateness = 30 * time.Second
+
+// DefaultCooldown is how long a key must stay quiet before it alerts again.
+const DefaultCooldown = 5 * time.Minute
+
+// Default returns the settings used when nothing is configured.
+func Default() Config {
+	return Config{
+		Inputs:      []string{"./logs/*.log"},
+		Merge:       true,
+		MergeBuffer: ingest.DefaultLookahead,
+		Lateness:    rules.Duration(DefaultLateness),
+		MaxKeys:     DefaultMaxKeys,
+		Cooldown:    rules.Duration(DefaultCooldown),
+		Summary:     true,
+		Workers:     1,
+		RulesDir:    "./rules",
+		Output:      output.Text,
+		Verbosity:   1,
+	}
+}
+
+// Load reads a YAML config file on top of Default().
+func Load(path string) (Config, error) {
+	c := Default()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, c.Validate()
+}
+
+// Validate checks values that do not need the rule set.
+func (c Config) Validate() error {
+	if c.Output != "" && !output.Valid(c.Output) {
+		return fmt.Errorf("unknown output format %q", c.Output)
+	}
+	if c.Lateness < 0 {
+		return errors.New("allowed_lateness must be >= 0")
+	}
+	if c.MaxKeys < 0 {
+		return errors.New("max_keys must be >= 0")
+	}
+	if _, err := detector.ParseEvictionPolicy(c.Eviction); err != nil {
+		return err
+	}
+	if c.Cooldown < 0 {
+		return errors.New("cooldown must be >= 0")
+	}
+	for id, d := range c.Detectors {
+		if d.Cooldown != nil && *d.Cooldown < 0 {
+			return fmt.Errorf("detectors.%s: cooldown must be >= 0", id)
+		}
+	}
+	if c.Workers < 0 {
+		return errors.New("workers must be >= 0")
+	}
+	if c.MergeBuffer < 0 {
+		return errors.New("merge_buffer must be >= 0")
+	}
+	if c.Verbosity < 0 {
+		return errors.New("verbosity must be >= 0")
+	}
+	for _, p := range c.Parsers {
+		if p.Name == "" {
+			return errors.New("parser without name")
+		}
+	}
+	return nil
+}
+
+// Detector returns the override for ruleID (zero if none).
+func (c *Config) Detector(ruleID string) DetectorConfig {
+	return c.Detectors[ruleID]
+}
+
+// SetDetector stores an override for ruleID.
+func (c *Config) SetDetector(ruleID string, d DetectorConfig) {
+	if c.Detectors == nil {
+		c.Detectors = make(map[string]DetectorConfig)
+	}
+	c.Detectors[ruleID] = d
+}
+
+// ExpandInputs resolves Inputs to file names: globs are expanded,
+// directories contribute their *.log files, "-" is kept for stdin.
+func (c Config) ExpandInputs() ([]string, error) {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	for _, in := range c.Inputs {
+		if in == normalizer.StdinSource {
+			add(in)
+			continue
+		}
+		if fi, err := os.Stat(in); err == nil && fi.IsDir() {
+			in = filepath.Join(in, "*.log")
+		}
+		matches, err := filepath.Glob(in)
+		if err != nil {
+			return nil, fmt.Errorf("input %q: %w", in, err)
+		}
+		sort.Strings(matches)
+		for _, m := range matches {
+			add(m)
+		}
+	}
+	return out, nil
+}
+
+// Registry builds the parser registry: custom parsers, source bindings
+// and syslog program bindings.
+func (c Config) Registry() (*normalizer.Registry, error) {
+	reg := normalizer.NewRegistry()
+	for _, p := range c.Parsers {
+		switch p.Type {
+		case "access":
+			ap, err := normalizer.NewAccessLogParser(p.LogFormat)
+			if err != nil {
+				return nil, fmt.Errorf("parser %s: %w", p.Name, err)
+			}
+			reg.Register(p.Name, ap)
+		case "json":
+			m := normalizer.DefaultJSONMapping
+			if p.Mapping != nil {
+				m = *p.Mapping
+			}
+			reg.Register(p.Name, normalizer.NewJSONParser(m))
+		default:
+			return nil, fmt.Errorf("parser %s: unknown type %q", p.Name, p.Type)
+		}
+	}
+	for _, b := range c.Bindings {
+		if err := reg.Bind(b.Source, b.Parser); err != nil {
+			return nil, err
+		}
+	}
+	programs := make([]string, 0, len(c.Programs))
+	for prog := range c.Programs {
+		programs = append(programs, prog)
+	}
+	sort.Strings(programs)
+	for _, prog := range programs {
+		if err := reg.BindProgram(prog, c.Programs[prog]); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+// Engine loads the rules (defaults + RulesDir), applies the detector
+// overrides and adds the built-in Go detectors.
+func (c Config) Engine() (*rules.Engine, error) {
+	build, err := c.detectors()
+	if err != nil {
+		return nil, err
+	}
+	engine := rules.NewEngine(build()...)
+	engine.SetLateness(time.Duration(c.Lateness))
+	return engine, nil
+}
+
+// Stage builds the detection stage that hands results to sink: a serial
+// Engine, or a ShardedEngine when Workers > 1. The rules are loaded once;
+// each shard gets its own detectors.
+func (c Config) Stage(sink rules.Sink) (rules.Stage, error) {
+	if c.Workers <= 1 {
+		engine, err := c.Engine()
+		if err != nil {
+			return nil, err
+		}
+		return rules.Serial(engine, sink), nil
+	}
+	build, err := c.detectors()
+	if err != nil {
+		return nil, err
+	}
+	se := rules.NewShardedEngine(c.Workers, build, sink)
+	se.SetLateness(time.Duration(c.Lateness))
+	return se, nil
+}
+
+// detectors loads and checks the rules and detector settings, registers
+// the rules' display text with format, and returns a function that builds
+// a fresh set of detectors, each wrapped in its alert suppression, on
+// every call.
+func (c Config) detectors() (func() []detector.Detector, error) {
+	rs, err := rules.Load(c.RulesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for i := range rs {
+		r := &rs[i]
+		known[r.ID] = true
+		d, ok := c.Detectors[r.ID]
+		if !ok {
+			continue
+		}
+		if !d.enabled() {
+			r.Disabled = true
+		}
+		if d.Window > 0 {
+			r.Window = d.Window
+		}
+		if d.Threshold > 0 {
+			r.Threshold = d.Threshold
+		}
+	}
+	for i := range rs {
+		r := &rs[i]
+		limits, err := c.limits(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		// 탐지기별 설정 > 룰 파일 > 전역 설정 순
+		d := c.Detectors[r.ID]
+		if d.MaxKeys > 0 || r.MaxKeys == 0 {
+			r.MaxKeys = limits.MaxKeys
+		}
+		if d.Eviction != "" || r.Eviction == "" {
+			r.Eviction = limits.Policy.String()
+		}
+	}
+
+	compiled, err := rules.Compile(rs)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rs {
+		if info, ok := r.Info(); ok && !r.Disabled {
+			format.Register(r.ID, info)
+		}
+	}
+	var builtin []func() detector.Detector
+
+	// 시퀀스 탐지: 실패 반복 → 로그인 성공 → 비밀번호 변경 (계정 탈취)
+	ato := detector.ATOConfig{Window: 60 * time.Second, MinFailures: 5}
+	if d, ok := c.override(known, "ACCOUNT_TAKEOVER"); ok {
+		if ato.Limits, err = c.limits("ACCOUNT_TAKEOVER"); err != nil {
+			return nil, err
+		}
+		setDuration(&ato.Window, d.Window)
+		setInt(&ato.MinFailures, d.MinFailures, d.Threshold)
+		builtin = append(builtin, func() detector.Detector { return detector.NewATODetector(ato) })
+	}
+
+	// 패스워드 스프레이: 한 IP에서 여러 계정 로그인 실패 (auth + ssh)
+	spray := detector.PasswordSprayConfig{Window: 5 * time.Minute, DistinctUsers: 4}
+	if d, ok := c.override(known, "PASSWORD_SPRAY"); ok {
+		if spray.Limits, err = c.limits("PASSWORD_SPRAY"); err != nil {
+			return nil, err
+		}
+		setDuration(&spray.Window, d.Window)
+		setInt(&spray.DistinctUsers, d.DistinctUsers, d.Threshold)
+		builtin = append(builtin, func() detector.Detector { return detector.NewPasswordSprayDetector(spray) })
+	}
+
+	// 분산 브루트포스: 한 계정에 여러 IP에서 로그인 실패 (auth + ssh)
+	dist := detector.DistributedBruteForceConfig{Window: 5 * time.Minute, MinFailures: 10, DistinctIPs: 5}
+	if d, ok := c.override(known, "DISTRIBUTED_BRUTE_FORCE"); ok {
+		if dist.Limits, err = c.limits("DISTRIBUTED_BRUTE_FORCE"); err != nil {
+			return nil, err
+		}
+		setDuration(&dist.Window, d.Window)
+		setInt(&dist.MinFailures, d.MinFailures)
+		setInt(&dist.DistinctIPs, d.DistinctIPs, d.Threshold)
+		builtin = append(builtin, func() detector.Detector { return detector.NewDistributedBruteForceDetector(dist) })
+	}
+
+	for id := range c.Detectors {
+		if !known[id] {
+			return nil, fmt.Errorf("detectors: unknown rule ID %q", id)
+		}
+	}
+
+	// 반복 탐지 억제: 쿨다운 동안 같은 키의 재탐지는 진행 중인 경고 하나로 합침
+	suppress := make(map[string]detector.SuppressConfig, len(known))
+	for id := range known {
+		if suppress[id], err = c.suppression(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return func() []detector.Detector {
+		ds := compiled()
+		for _, build := range builtin {
+			ds = append(ds, build())
+		}
+		for i, d := range ds {
+			ds[i] = detector.Suppress(d, suppress[d.RuleID()])
+		}
+		return ds
+	}, nil
+}
+
+// limits returns the state limits for ruleID: its own override, else
+// the global max_keys/eviction.
+func (c Config) limits(ruleID string) (detector.StateLimits, error) {
+	d := c.Detectors[ruleID]
+	maxKeys, eviction := c.MaxKeys, c.Eviction
+	if d.MaxKeys > 0 {
+		maxKeys = d.MaxKeys
+	}
+	if d.Eviction != "" {
+		eviction = d.Eviction
+	}
+	policy, err := detector.ParseEvictionPolicy(eviction)
+	if err != nil {
+		return detector.StateLimits{}, fmt.Errorf("detectors.%s: %w", ruleID, err)
+	}
+	return detector.StateLimits{MaxKeys: maxKeys, Policy: policy}, nil
+}
+
+// suppression returns the alert suppression for ruleID: its own
+// cooldown, else the global one, with open incidents capped like the
+// detector's keys.
+func (c Config) suppression(ruleID string) (detector.SuppressConfig, error) {
+	cooldown := c.Cooldown
+	if d := c.Detectors[ruleID]; d.Cooldown != nil {
+		cooldown = *d.Cooldown
+	}
+	limits, err := c.limits(ruleID)
+	if err != nil {
+		return detector.SuppressConfig{}, err
+	}
+	return detector.SuppressConfig{
+		Cooldown: time.Duration(cooldown),
+		Summary:  c.Summary,
+		MaxOpen:  limits.MaxKeys,
+	}, nil
+}
+
+// override marks ruleID as known and returns its override and whether the
+// detector is enabled.
+func (c Config) override(known map[string]bool, ruleID string) (DetectorConfig, bool) {
+	known[ruleID] = true
+	d := c.Detectors[ruleID]
+	return d, d.enabled()
+}
+
+func setDuration(dst *time.Duration, v rules.Duration) {
+	if v > 0 {
+		*dst = time.Duration(v)
+	}
+}
+
+// setInt stores the first positive value.
+func setInt(dst *int, vals ...int) {
+	for _, v := range vals {
+		if v > 0 {
+			*dst = v
+			return
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/ingest"
+	"go-logshield/internal/normalizer"
+	"go-logshield/internal/rules"
+)
+
+// run feeds streams, merged like cmd/logshield does, through cfg.Stage
+// and returns every alert in sink order and the late count.
+func run(t *testing.T, cfg Config, streams func(reg *normalizer.Registry) []ingest.Stream) ([]detector.Alert, uint64) {
+	t.Helper()
+	reg, err := cfg.Registry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var alerts []detector.Alert
+	stage, err := cfg.Stage(func(_ normalizer.Event, as []detector.Alert) {
+		alerts = append(alerts, as...)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := ingest.NewMerger(cfg.MergeBuffer, streams(reg)...)
+	for {
+		it, ok := m.Next()
+		if !ok {
+			break
+		}
+		if it.Err == nil {
+			stage.Submit(it.Event)
+		}
+	}
+	stage.Close()
+	return alerts, stage.Late()
+}
+
+// sampleLogs reads the files under logs/.
+func sampleLogs(t *testing.T) func(reg *normalizer.Registry) []ingest.Stream {
+	cfg := Default()
+	cfg.Inputs = []string{"../../logs/*.log"}
+	paths, err := cfg.ExpandInputs()
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("sample logs: %v %v", paths, err)
+	}
+	return func(reg *normalizer.Registry) []ingest.Stream {
+		var streams []ingest.Stream
+		for _, p := range paths {
+			f, err := os.Open(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { f.Close() })
+			streams = append(streams, ingest.NewLineStream(f, reg.Source(p)))
+		}
+		return streams
+	}
+}
+
+// attackLogs is a failed-login storm over many IPs and accounts, with
+// neighbouring lines swapped so some events arrive late, and a few
+// too late to be used.
+func attackLogs(reg *normalizer.Registry) []ingest.Stream {
+	t0 := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	var lines []string
+	for i := range 600 {
+		status := "FAIL"
+		if i%17 == 0 {
+			status = "SUCCESS"
+		}
+		ts := t0.Add(time.Duration(i) * 700 * time.Millisecond)
+		if i%97 == 50 {
+			ts = ts.Add(-2 * time.Minute) // allowed_lateness 밖
+		}
+		lines = append(lines, fmt.Sprintf("%s service=auth action=login user=u%d ip=10.0.%d.%d status=%s",
+			ts.Format(time.RFC3339Nano), i%9, i%2, i%3, status))
+	}
+	for i := 0; i+1 < len(lines); i += 5 {
+		lines[i], lines[i+1] = lines[i+1], lines[i]
+	}
+	half := len(lines) / 2
+	return []ingest.Stream{
+		ingest.NewLineStream(strings.NewReader(strings.Join(lines[:half], "\n")), reg.Source("a.log")),
+		ingest.NewLineStream(strings.NewReader(strings.Join(lines[half:], "\n")), reg.Source("b.log")),
+	}
+}
+
+// quietLogs is a brute force from one IP that goes quiet, then bursts
+// from other IPs after its cooldown has run out, so the first IP's
+// incident summary must come out between their alerts. Every IP tries
+// its own account so only BRUTE_FORCE_LOGIN fires.
+func quietLogs(reg *normalizer.Registry) []ingest.Stream {
+	t0 := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	line := func(sec int, ip string) string {
+		return fmt.Sprintf("%s service=auth action=login user=u-%s ip=%s status=FAIL",
+			t0.Add(time.Duration(sec)*time.Second).Format(time.RFC3339), ip, ip)
+	}
+	var lines []string
+	for i := range 12 {
+		lines = append(lines, line(i, "10.9.0.1"))
+	}
+	for b := range 6 {
+		ip := fmt.Sprintf("10.9.1.%d", b+1)
+		for i := range 6 {
+			lines = append(lines, line(int(DefaultCooldown/time.Second)+60+b*30+i, ip))
+		}
+	}
+	return []ingest.Stream{ingest.NewLineStream(strings.NewReader(strings.Join(lines, "\n")), reg.Source("quiet.log"))}
+}
+
+func TestWorkersMatchSerial(t *testing.T) {
+	tests := []struct {
+		name     string
+		streams  func(reg *normalizer.Registry) []ingest.Stream
+		cooldown time.Duration
+		summary  int // index of the first summary in the serial run; 0 = not checked
+	}{
+		{"sample logs", sampleLogs(t), DefaultCooldown, 0},
+		{"sample logs, no cooldown", sampleLogs(t), 0, 0},
+		{"attack", attackLogs, DefaultCooldown, 0},
+		{"attack, no cooldown", attackLogs, 0, 0},
+		{"cooldown expires on an idle shard", quietLogs, DefaultCooldown, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.RulesDir = ""
+			cfg.Cooldown = rules.Duration(tt.cooldown)
+
+			want, wantLate := run(t, cfg, tt.streams)
+			if len(want) == 0 {
+				t.Fatal("serial run raised no alerts")
+			}
+			if tt.summary > 0 && (len(want) <= tt.summary || !want[tt.summary].Summary) {
+				t.Fatalf("serial run: no summary at %d", tt.summary)
+			}
+			for _, workers := range []int{2, 4, 7} {
+				cfg.Workers = workers
+				got, late := run(t, cfg, tt.streams)
+				if late != wantLate {
+					t.Errorf("workers=%d: late %d, want %d", workers, late, wantLate)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("workers=%d: %d alerts differ from the %d serial ones", workers, len(got), len(want))
+					for i := range min(len(got), len(want)) {
+						if !reflect.DeepEqual(got[i], want[i]) {
+							t.Errorf("first difference at %d:\n got %s %s %v\nwant %s %s %v", i,
+								got[i].RuleID, got[i].Key, got[i].LastSeen, want[i].RuleID, want[i].Key, want[i].LastSeen)
+							break
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDetectorLimits(t *testing.T) {
+	dir := t.TempDir()
+	rule := "id: LIMITS\nseverity: low\nmatch:\n  - field: service\n    equals: test\ngroup_by: ip\nwindow: 1m\nthreshold: 100\nmax_keys: 3\n"
+	if err := os.WriteFile(dir+"/limits.yaml", []byte(rule), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		override DetectorConfig
+		evicted  uint64
+	}{
+		{"rule file over global", DetectorConfig{}, 2},
+		{"detector over rule file", DetectorConfig{MaxKeys: 4}, 1},
+		{"detector eviction only", DetectorConfig{Eviction: "lowest_count"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.RulesDir = dir
+			cfg.MaxKeys = 10
+			cfg.Detectors = map[string]DetectorConfig{"LIMITS": tt.override}
+			engine, err := cfg.Engine()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t0 := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+			for i := range 5 {
+				engine.Process(normalizer.Event{TS: t0.Add(time.Duration(i) * time.Second), Service: "test", IP: fmt.Sprintf("10.0.0.%d", i)})
+			}
+			found := false
+			for _, st := range engine.Stats() {
+				if st.RuleID != "LIMITS" {
+					continue
+				}
+				found = true
+				if st.Evicted != tt.evicted {
+					t.Errorf("evicted %d keys, want %d", st.Evicted, tt.evicted)
+				}
+			}
+			if !found {
+				t.Fatal("no state stats for LIMITS")
+			}
+		})
+	}
+}
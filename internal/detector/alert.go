@@ -0,0 +1,113 @@
+package detector
+
+import (
+	"fmt"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+// Severity is the alert level. Zero value is "unknown".
+type Severity int
+
+const (
+	SeverityLow Severity = iota + 1
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSeverity accepts "low", "medium", "high", "critical".
+func ParseSeverity(s string) (Severity, error) {
+	switch s {
+	case "low":
+		return SeverityLow, nil
+	case "medium":
+		return SeverityMedium, nil
+	case "high":
+		return SeverityHigh, nil
+	case "critical":
+		return SeverityCritical, nil
+	default:
+		return 0, fmt.Errorf("unknown severity %q", s)
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(b []byte) error {
+	v, err := ParseSeverity(string(b))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// Alert is what a Detector raises. Rendering (Korean text, report.json)
+// lives in internal/format; detectors only fill in the facts.
+type Alert struct {
+	RuleID   string
+	Severity Severity
+
+	// Key is the group-by entity the rule counted on (usually an IP).
+	Key    string
+	Count  int
+	Window time.Duration
+
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// Events that contributed to the alert, oldest first.
+	Events []normalizer.Event
+
+	// Related lists other entities involved, keyed by kind
+	// (RelatedUsers, RelatedIPs, ...), most significant first.
+	Related map[string][]string
+
+	// Summary marks the closing alert of an incident (see Suppress):
+	// Count, FirstSeen and LastSeen then cover the whole incident, and
+	// Suppressed is the number of repeat triggers merged into it.
+	Summary    bool
+	Suppressed int
+}
+
+// Keys of Alert.Related.
+const (
+	RelatedUsers   = "users"
+	RelatedIPs     = "ips"
+	RelatedSubnets = "subnets"
+)
+
+// Detector consumes normalized events one at a time.
+// Process returns (alert, true) when the rule triggers.
+type Detector interface {
+	RuleID() string
+	Process(ev normalizer.Event) (Alert, bool)
+}
+
+// Keyed is implemented by detectors whose state is partitioned by an
+// entity key (IP, user). Key returns the key ev would be filed under, or
+// "" when the detector ignores ev. It must only read configuration, not
+// state, so it can be called from another goroutine to route events to
+// shards (see rules.ShardedEngine).
+type Keyed interface {
+	Key(ev normalizer.Event) string
+}
@@ -0,0 +1,148 @@
+package detector
+
+import (
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+// ATOConfig configures the account takeover sequence:
+// MinFailures x login FAIL -> login SUCCESS -> sensitive action,
+// all for one user within Window.
+type ATOConfig struct {
+	Window      time.Duration
+	MinFailures int
+
+	// Actions treated as "sensitive" once the account is taken over.
+	// Defaults to password_change.
+	SensitiveActions []string
+
+	Limits StateLimits
+}
+
+type ATODetector struct {
+	cfg   ATOConfig
+	clock eventClock
+
+	// user -> login results and sensitive actions (time-sorted)
+	users *keyedState
+}
+
+func NewATODetector(cfg ATOConfig) *ATODetector {
+	if len(cfg.SensitiveActions) == 0 {
+		cfg.SensitiveActions = []string{"password_change"}
+	}
+	return &ATODetector{
+		cfg:   cfg,
+		users: newKeyedState(cfg.Limits),
+	}
+}
+
+func (d *ATODetector) RuleID() string { return "ACCOUNT_TAKEOVER" }
+
+func (d *ATODetector) Advance(wm time.Time) {
+	d.clock.Advance(wm)
+	d.users.maybeSweep(d.clock.wm, d.cfg.Window)
+}
+
+func (d *ATODetector) StateStats() StateStats { return d.users.stats() }
+
+func (d *ATODetector) isSensitive(action string) bool {
+	for _, a := range d.cfg.SensitiveActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// relevant reports whether ev can take part in the sequence.
+func (d *ATODetector) relevant(ev normalizer.Event) bool {
+	if ev.Action == "login" {
+		return ev.Status == "FAIL" || ev.Status == "SUCCESS"
+	}
+	return d.isSensitive(ev.Action) && ev.Status != "FAIL"
+}
+
+func (d *ATODetector) Key(ev normalizer.Event) string {
+	if ev.Service != "auth" || ev.User == "" || !d.relevant(ev) {
+		return ""
+	}
+	return ev.User
+}
+
+func (d *ATODetector) Process(ev normalizer.Event) (Alert, bool) {
+	if d.Key(ev) == "" {
+		return Alert{}, false
+	}
+
+	// 늦게 도착한 이벤트도 시간 순서대로 끼워 넣고 시퀀스를 처음부터 다시 확인
+	wm := d.clock.watermark(ev.TS)
+	d.users.maybeSweep(wm, d.cfg.Window)
+	list := d.users.get(ev.User).insert(ev).evict(wm.Add(-d.cfg.Window))
+
+	chain := d.sequence(list)
+	if chain == nil {
+		d.users.put(ev.User, list)
+		return Alert{}, false
+	}
+	a := Alert{
+		RuleID:    d.RuleID(),
+		Severity:  SeverityCritical,
+		Key:       ev.User,
+		Count:     len(chain) - 2, // failures only
+		Window:    d.cfg.Window,
+		FirstSeen: chain[0].TS,
+		LastSeen:  chain[len(chain)-1].TS,
+		Events:    chain,
+	}
+	// 완성된 체인은 소비: 다음 알림에는 새 체인이 필요 (반복은 Suppressor가 합침)
+	d.users.del(ev.User)
+	return a, true
+}
+
+// sequence replays list in time order and returns the first complete
+// chain (failures, success, sensitive action), or nil.
+func (d *ATODetector) sequence(list window) []normalizer.Event {
+	var (
+		// login failures since the last success (sliding window)
+		failures []normalizer.Event
+		// failures + success once the account looks compromised; nil otherwise
+		chain []normalizer.Event
+	)
+	for _, ev := range list {
+		// 시퀀스 시작(첫 실패)이 윈도우 밖으로 나가면 진행 중인 체인은 폐기
+		cutoff := ev.TS.Add(-d.cfg.Window)
+		if chain != nil && chain[0].TS.Before(cutoff) {
+			chain = nil
+		}
+		j := 0
+		for _, e := range failures {
+			if !e.TS.Before(cutoff) {
+				failures[j] = e
+				j++
+			}
+		}
+		failures = failures[:j]
+
+		switch {
+		case ev.Action == "login" && ev.Status == "FAIL":
+			if chain == nil {
+				failures = append(failures, ev)
+			}
+
+		case ev.Action == "login" && ev.Status == "SUCCESS":
+			// 실패가 충분히 쌓인 뒤의 성공만 의심 — 오타 몇 번은 정상
+			if chain == nil && len(failures) >= d.cfg.MinFailures {
+				chain = append(failures, ev)
+			}
+			failures = nil
+
+		default: // sensitive action
+			if chain != nil {
+				return append(chain, ev)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package detector
+
+import (
+	"testing"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+var t0 = time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+
+func at(sec int) time.Time { return t0.Add(time.Duration(sec) * time.Second) }
+
+// login is an auth event at t0+sec.
+func login(sec int, user, ip, action, status string) normalizer.Event {
+	return normalizer.Event{TS: at(sec), Service: "auth", Action: action, User: user, IP: ip, Status: status}
+}
+
+// firstAlert feeds evs to d in order and returns the first alert and the
+// index of the event that raised it (-1 for none).
+func firstAlert(d Detector, evs []normalizer.Event) (Alert, int) {
+	for i, ev := range evs {
+		if a, ok := d.Process(ev); ok {
+			return a, i
+		}
+	}
+	return Alert{}, -1
+}
+
+// fails is n failed logins of user, one per second from sec.
+func fails(sec, n int, user, ip string) []normalizer.Event {
+	var out []normalizer.Event
+	for i := range n {
+		out = append(out, login(sec+i, user, ip, "login", "FAIL"))
+	}
+	return out
+}
+
+func seq(parts ...[]normalizer.Event) []normalizer.Event {
+	var out []normalizer.Event
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func one(ev normalizer.Event) []normalizer.Event { return []normalizer.Event{ev} }
+
+func TestATODetector(t *testing.T) {
+	const ip = "203.0.113.5"
+	tests := []struct {
+		name    string
+		cfg     ATOConfig
+		events  []normalizer.Event
+		at      int // index of the alerting event, -1 for none
+		count   int
+		first   int
+		last    int
+		chained int // len(Events)
+	}{
+		{
+			name: "failures, success, password change",
+			events: seq(fails(0, 5, "alice", ip),
+				one(login(10, "alice", ip, "login", "SUCCESS")),
+				one(login(20, "alice", ip, "password_change", "SUCCESS"))),
+			at: 6, count: 5, first: 0, last: 20, chained: 7,
+		},
+		{
+			name: "too few failures",
+			events: seq(fails(0, 4, "alice", ip),
+				one(login(10, "alice", ip, "login", "SUCCESS")),
+				one(login(20, "alice", ip, "password_change", "SUCCESS"))),
+			at: -1,
+		},
+		{
+			name: "sensitive action outside the window",
+			events: seq(fails(0, 5, "alice", ip),
+				one(login(10, "alice", ip, "login", "SUCCESS")),
+				one(login(70, "alice", ip, "password_change", "SUCCESS"))),
+			at: -1,
+		},
+		{
+			name: "failures older than the window do not count",
+			events: seq(fails(0, 3, "alice", ip), fails(50, 2, "alice", ip),
+				one(login(62, "alice", ip, "login", "SUCCESS")),
+				one(login(63, "alice", ip, "password_change", "SUCCESS"))),
+			at: -1,
+		},
+		{
+			name: "failed password change",
+			events: seq(fails(0, 5, "alice", ip),
+				one(login(10, "alice", ip, "login", "SUCCESS")),
+				one(login(20, "alice", ip, "password_change", "FAIL"))),
+			at: -1,
+		},
+		{
+			name: "success before the failures",
+			events: seq(one(login(0, "alice", ip, "login", "SUCCESS")), fails(1, 5, "alice", ip),
+				one(login(20, "alice", ip, "password_change", "SUCCESS"))),
+			at: -1,
+		},
+		{
+			name: "other user's success",
+			events: seq(fails(0, 5, "alice", ip),
+				one(login(10, "bob", ip, "login", "SUCCESS")),
+				one(login(20, "alice", ip, "password_change", "SUCCESS"))),
+			at: -1,
+		},
+		{
+			name: "custom sensitive action",
+			cfg:  ATOConfig{SensitiveActions: []string{"mfa_disable"}},
+			events: seq(fails(0, 5, "alice", ip),
+				one(login(10, "alice", ip, "login", "SUCCESS")),
+				one(login(15, "alice", ip, "password_change", "SUCCESS")),
+				one(login(20, "alice", ip, "mfa_disable", "SUCCESS"))),
+			at: 7, count: 5, first: 0, last: 20, chained: 7,
+		},
+		{
+			name: "ssh events are ignored",
+			events: seq(fails(0, 5, "alice", ip),
+				one(normalizer.Event{TS: at(10), Service: "ssh", Action: "login", User: "alice", Status: "SUCCESS"}),
+				one(login(20, "alice", ip, "password_change", "SUCCESS"))),
+			at: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Window, cfg.MinFailures = 60*time.Second, 5
+			a, i := firstAlert(NewATODetector(cfg), tt.events)
+			if i != tt.at {
+				t.Fatalf("alert at event %d, want %d", i, tt.at)
+			}
+			if i < 0 {
+				return
+			}
+			if a.RuleID != "ACCOUNT_TAKEOVER" || a.Severity != SeverityCritical || a.Key != "alice" {
+				t.Errorf("alert %s/%v/%s", a.RuleID, a.Severity, a.Key)
+			}
+			if a.Count != tt.count || !a.FirstSeen.Equal(at(tt.first)) || !a.LastSeen.Equal(at(tt.last)) || len(a.Events) != tt.chained {
+				t.Errorf("count=%d first=%v last=%v events=%d, want %d [%ds, %ds] %d",
+					a.Count, a.FirstSeen, a.LastSeen, len(a.Events), tt.count, tt.first, tt.last, tt.chained)
+			}
+		})
+	}
+}
+
+func TestATODetectorConsumesChain(t *testing.T) {
+	d := NewATODetector(ATOConfig{Window: 60 * time.Second, MinFailures: 5})
+	evs := seq(fails(0, 5, "alice", "1.2.3.4"),
+		one(login(10, "alice", "1.2.3.4", "login", "SUCCESS")),
+		one(login(20, "alice", "1.2.3.4", "password_change", "SUCCESS")))
+	if _, i := firstAlert(d, evs); i != 6 {
+		t.Fatalf("alert at %d, want 6", i)
+	}
+	// 같은 체인으로 두 번째 알림은 없음
+	if _, ok := d.Process(login(25, "alice", "1.2.3.4", "password_change", "SUCCESS")); ok {
+		t.Error("second password change alerted again on the consumed chain")
+	}
+}
+
+func TestATODetectorOutOfOrder(t *testing.T) {
+	const ip = "203.0.113.5"
+	tests := []struct {
+		name   string
+		events []normalizer.Event
+		at     int
+	}{
+		{
+			// 성공 로그가 비밀번호 변경보다 늦게 도착
+			name: "late success",
+			events: seq(fails(0, 5, "alice", ip),
+				one(login(20, "alice", ip, "password_change", "SUCCESS")),
+				one(login(10, "alice", ip, "login", "SUCCESS"))),
+			at: 6,
+		},
+		{
+			name: "late failures",
+			events: seq(fails(0, 3, "alice", ip),
+				one(login(10, "alice", ip, "login", "SUCCESS")),
+				one(login(20, "alice", ip, "password_change", "SUCCESS")),
+				fails(3, 2, "alice", ip)),
+			at: 6,
+		},
+		{
+			// 늦게 온 성공이 변경보다 나중 시각이면 순서가 맞지 않음
+			name: "success after the change",
+			events: seq(fails(0, 5, "alice", ip),
+				one(login(20, "alice", ip, "password_change", "SUCCESS")),
+				one(login(25, "alice", ip, "login", "SUCCESS"))),
+			at: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, i := firstAlert(NewATODetector(ATOConfig{Window: 60 * time.Second, MinFailures: 5}), tt.events)
+			if i != tt.at {
+				t.Fatalf("alert at event %d, want %d", i, tt.at)
+			}
+			if i >= 0 && (a.Count != 5 || !a.FirstSeen.Equal(at(0)) || !a.LastSeen.Equal(at(20))) {
+				t.Errorf("count=%d first=%v last=%v, want 5 in [0s, 20s]", a.Count, a.FirstSeen, a.LastSeen)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package detector
+
+import (
+	"net/netip"
+	"sort"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+// DistributedBruteForceConfig: one account failing MinFailures times from
+// at least DistinctIPs source addresses within Window.
+type DistributedBruteForceConfig struct {
+	Window      time.Duration
+	MinFailures int
+	DistinctIPs int
+
+	// TopN limits the IPs/subnets listed in the alert (default 5).
+	TopN int
+
+	Limits StateLimits
+}
+
+type DistributedBruteForceDetector struct {
+	cfg   DistributedBruteForceConfig
+	clock eventClock
+
+	// user -> failure events (sliding window, time-sorted)
+	failures *keyedState
+}
+
+func NewDistributedBruteForceDetector(cfg DistributedBruteForceConfig) *DistributedBruteForceDetector {
+	if cfg.TopN <= 0 {
+		cfg.TopN = 5
+	}
+	return &DistributedBruteForceDetector{
+		cfg:      cfg,
+		failures: newKeyedState(cfg.Limits),
+	}
+}
+
+func (d *DistributedBruteForceDetector) RuleID() string { return "DISTRIBUTED_BRUTE_FORCE" }
+
+func (d *DistributedBruteForceDetector) Advance(wm time.Time) {
+	d.clock.Advance(wm)
+	d.failures.maybeSweep(d.clock.wm, d.cfg.Window)
+}
+
+func (d *DistributedBruteForceDetector) StateStats() StateStats { return d.failures.stats() }
+
+// distinctIPs counts the source addresses in list.
+func distinctIPs(list window) int {
+	seen := make(map[string]bool)
+	for _, e := range list {
+		seen[e.IP] = true
+	}
+	return len(seen)
+}
+
+func (d *DistributedBruteForceDetector) Key(ev normalizer.Event) string {
+	if !isAuthFailure(ev) || ev.IP == "" || ev.User == "" {
+		return ""
+	}
+	return ev.User
+}
+
+func (d *DistributedBruteForceDetector) Process(ev normalizer.Event) (Alert, bool) {
+	user := d.Key(ev)
+	if user == "" {
+		return Alert{}, false
+	}
+
+	wm := d.clock.watermark(ev.TS)
+	d.failures.maybeSweep(wm, d.cfg.Window)
+	list := d.failures.get(user).insert(ev).evict(wm.Add(-d.cfg.Window))
+
+	run := list.span(ev.TS, d.cfg.Window, func(run window) bool {
+		return len(run) >= d.cfg.MinFailures && distinctIPs(run) >= d.cfg.DistinctIPs
+	})
+	if run == nil {
+		d.failures.put(user, list)
+		return Alert{}, false
+	}
+
+	ipCount := make(map[string]int)
+	subnetCount := make(map[string]int)
+	for _, e := range run {
+		ipCount[e.IP]++
+		subnetCount[subnetOf(e.IP)]++
+	}
+
+	a := Alert{
+		RuleID:    d.RuleID(),
+		Severity:  SeverityHigh,
+		Key:       user,
+		Count:     len(run),
+		Window:    d.cfg.Window,
+		FirstSeen: run[0].TS,
+		LastSeen:  run[len(run)-1].TS,
+		Events:    append([]normalizer.Event(nil), run...),
+		Related: map[string][]string{
+			RelatedIPs:     topKeys(ipCount, d.cfg.TopN),
+			RelatedSubnets: topKeys(subnetCount, d.cfg.TopN),
+		},
+	}
+
+	d.failures.put(user, list)
+	return a, true
+}
+
+func (d *DistributedBruteForceDetector) Reset(key string) { d.failures.del(key) }
+
+// subnetOf returns the /24 (IPv4) or /64 (IPv6) containing ip,
+// or ip itself when it does not parse.
+func subnetOf(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	bits := 64
+	if addr.Is4() || addr.Is4In6() {
+		addr = addr.Unmap()
+		bits = 24
+	}
+	p, err := addr.Prefix(bits)
+	if err != nil {
+		return ip
+	}
+	return p.String()
+}
+
+// topKeys returns up to n keys of counts, highest count first
+// (ties broken by key for stable output).
+func topKeys(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
@@ -0,0 +1,121 @@
+package detector
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+func TestDistributedBruteForceDetector(t *testing.T) {
+	// spread is n failures of user, cycling through ips, one every 10s.
+	spread := func(user string, n int, ips ...string) []normalizer.Event {
+		var out []normalizer.Event
+		for i := range n {
+			out = append(out, login(i*10, user, ips[i%len(ips)], "login", "FAIL"))
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		events  []normalizer.Event
+		at      int
+		count   int
+		ips     []string
+		subnets []string
+	}{
+		{
+			name:    "many IPs against one account",
+			events:  spread("alice", 10, "10.0.1.1", "10.0.1.2", "10.0.2.3", "10.0.1.4", "10.0.2.5"),
+			at:      9,
+			count:   10,
+			ips:     []string{"10.0.1.1", "10.0.1.2", "10.0.1.4", "10.0.2.3", "10.0.2.5"},
+			subnets: []string{"10.0.1.0/24", "10.0.2.0/24"},
+		},
+		{
+			name: "top IPs by count",
+			events: spread("alice", 12, "10.0.1.1", "10.0.1.1", "10.0.1.1", "10.0.1.2", "10.0.1.2",
+				"10.0.1.3", "10.0.1.4", "10.0.1.5", "10.0.1.6", "10.0.1.7", "10.0.1.8", "10.0.1.9"),
+			at:      9,
+			count:   10,
+			ips:     []string{"10.0.1.1", "10.0.1.2", "10.0.1.3", "10.0.1.4", "10.0.1.5"},
+			subnets: []string{"10.0.1.0/24"},
+		},
+		{
+			name:    "IPv6 sources group by /64",
+			events:  spread("alice", 10, "2001:db8::1", "2001:db8::2", "2001:db8:0:1::1", "2001:db8::3", "2001:db8::4"),
+			at:      9,
+			count:   10,
+			ips:     []string{"2001:db8:0:1::1", "2001:db8::1", "2001:db8::2", "2001:db8::3", "2001:db8::4"},
+			subnets: []string{"2001:db8::/64", "2001:db8:0:1::/64"},
+		},
+		{
+			name:   "too few source IPs",
+			events: spread("alice", 20, "10.0.1.1", "10.0.1.2", "10.0.1.3", "10.0.1.4"),
+			at:     -1,
+		},
+		{
+			name:   "too few failures",
+			events: spread("alice", 9, "10.0.1.1", "10.0.1.2", "10.0.1.3", "10.0.1.4", "10.0.1.5"),
+			at:     -1,
+		},
+		{
+			name: "failures spread over different accounts",
+			events: func() []normalizer.Event {
+				var out []normalizer.Event
+				for i := range 10 {
+					out = append(out, login(i, fmt.Sprintf("user%d", i%2), fmt.Sprintf("10.0.1.%d", i), "login", "FAIL"))
+				}
+				return out
+			}(),
+			at: -1,
+		},
+		{
+			name: "failures wider than the window",
+			events: func() []normalizer.Event {
+				var out []normalizer.Event
+				for i := range 10 {
+					out = append(out, login(i*40, "alice", fmt.Sprintf("10.0.1.%d", i), "login", "FAIL"))
+				}
+				return out
+			}(),
+			at: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDistributedBruteForceDetector(DistributedBruteForceConfig{Window: 5 * time.Minute, MinFailures: 10, DistinctIPs: 5})
+			a, i := firstAlert(d, tt.events)
+			if i != tt.at {
+				t.Fatalf("alert at event %d, want %d", i, tt.at)
+			}
+			if i < 0 {
+				return
+			}
+			if a.RuleID != "DISTRIBUTED_BRUTE_FORCE" || a.Key != "alice" || a.Count != tt.count {
+				t.Errorf("alert %s key=%s count=%d, want alice/%d", a.RuleID, a.Key, a.Count, tt.count)
+			}
+			if got := a.Related[RelatedIPs]; !reflect.DeepEqual(got, tt.ips) {
+				t.Errorf("related ips %q, want %q", got, tt.ips)
+			}
+			if got := a.Related[RelatedSubnets]; !reflect.DeepEqual(got, tt.subnets) {
+				t.Errorf("related subnets %q, want %q", got, tt.subnets)
+			}
+		})
+	}
+}
+
+func TestSubnetOf(t *testing.T) {
+	for ip, want := range map[string]string{
+		"203.0.113.77":       "203.0.113.0/24",
+		"::ffff:203.0.113.7": "203.0.113.0/24",
+		"2001:db8:1:2:3::9":  "2001:db8:1:2::/64",
+		"not-an-ip":          "not-an-ip",
+	} {
+		if got := subnetOf(ip); got != want {
+			t.Errorf("subnetOf(%q) = %q, want %q", ip, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package detector
+
+import (
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+// PasswordSprayConfig: one IP failing against DistinctUsers different
+// accounts within Window, however slowly each account is tried.
+type PasswordSprayConfig struct {
+	Window        time.Duration
+	DistinctUsers int
+
+	Limits StateLimits
+}
+
+type PasswordSprayDetector struct {
+	cfg   PasswordSprayConfig
+	clock eventClock
+
+	// ip -> failure events (sliding window, time-sorted)
+	failures *keyedState
+}
+
+func NewPasswordSprayDetector(cfg PasswordSprayConfig) *PasswordSprayDetector {
+	return &PasswordSprayDetector{
+		cfg:      cfg,
+		failures: newKeyedState(cfg.Limits),
+	}
+}
+
+func (d *PasswordSprayDetector) RuleID() string { return "PASSWORD_SPRAY" }
+
+func (d *PasswordSprayDetector) Advance(wm time.Time) {
+	d.clock.Advance(wm)
+	d.failures.maybeSweep(d.clock.wm, d.cfg.Window)
+}
+
+func (d *PasswordSprayDetector) StateStats() StateStats { return d.failures.stats() }
+
+// isAuthFailure matches failed logins on both auth and ssh.
+func isAuthFailure(ev normalizer.Event) bool {
+	if ev.Status != "FAIL" {
+		return false
+	}
+	return (ev.Service == "auth" && ev.Action == "login") ||
+		(ev.Service == "ssh" && ev.Action == "auth")
+}
+
+// distinctUsers returns the users in list, in first-seen order.
+func distinctUsers(list []normalizer.Event) []string {
+	seen := make(map[string]bool)
+	var users []string
+	for _, e := range list {
+		if !seen[e.User] {
+			seen[e.User] = true
+			users = append(users, e.User)
+		}
+	}
+	return users
+}
+
+func (d *PasswordSprayDetector) Key(ev normalizer.Event) string {
+	if !isAuthFailure(ev) || ev.IP == "" || ev.User == "" {
+		return ""
+	}
+	return ev.IP
+}
+
+func (d *PasswordSprayDetector) Process(ev normalizer.Event) (Alert, bool) {
+	ip := d.Key(ev)
+	if ip == "" {
+		return Alert{}, false
+	}
+
+	wm := d.clock.watermark(ev.TS)
+	d.failures.maybeSweep(wm, d.cfg.Window)
+	list := d.failures.get(ip).insert(ev).evict(wm.Add(-d.cfg.Window))
+
+	run := list.span(ev.TS, d.cfg.Window, func(run window) bool {
+		return len(distinctUsers(run)) >= d.cfg.DistinctUsers
+	})
+	if run == nil {
+		d.failures.put(ip, list)
+		return Alert{}, false
+	}
+
+	a := Alert{
+		RuleID:    d.RuleID(),
+		Severity:  SeverityHigh,
+		Key:       ip,
+		Count:     len(run),
+		Window:    d.cfg.Window,
+		FirstSeen: run[0].TS,
+		LastSeen:  run[len(run)-1].TS,
+		Events:    append([]normalizer.Event(nil), run...),
+		Related:   map[string][]string{RelatedUsers: distinctUsers(run)},
+	}
+
+	d.failures.put(ip, list)
+	return a, true
+}
+
+func (d *PasswordSprayDetector) Reset(key string) { d.failures.del(key) }
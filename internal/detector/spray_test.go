@@ -0,0 +1,104 @@
+package detector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+func TestPasswordSprayDetector(t *testing.T) {
+	sshFail := func(sec int, user, ip string) normalizer.Event {
+		return normalizer.Event{TS: at(sec), Service: "ssh", Action: "auth", User: user, IP: ip, Status: "FAIL"}
+	}
+	const ip = "198.51.100.9"
+	tests := []struct {
+		name   string
+		events []normalizer.Event
+		at     int
+		count  int
+		users  []string
+	}{
+		{
+			name: "distinct users from one IP",
+			events: []normalizer.Event{
+				login(0, "alice", ip, "login", "FAIL"),
+				login(30, "bob", ip, "login", "FAIL"),
+				login(60, "alice", ip, "login", "FAIL"),
+				login(90, "carol", ip, "login", "FAIL"),
+				login(120, "dave", ip, "login", "FAIL"),
+			},
+			at: 4, count: 5, users: []string{"alice", "bob", "carol", "dave"},
+		},
+		{
+			name: "auth and ssh failures both count",
+			events: []normalizer.Event{
+				login(0, "alice", ip, "login", "FAIL"),
+				sshFail(10, "root", ip),
+				sshFail(20, "admin", ip),
+				login(30, "bob", ip, "login", "FAIL"),
+			},
+			at: 3, count: 4, users: []string{"alice", "root", "admin", "bob"},
+		},
+		{
+			name: "one user many times is not a spray",
+			events: []normalizer.Event{
+				login(0, "alice", ip, "login", "FAIL"), login(1, "alice", ip, "login", "FAIL"),
+				login(2, "alice", ip, "login", "FAIL"), login(3, "alice", ip, "login", "FAIL"),
+				login(4, "alice", ip, "login", "FAIL"), login(5, "alice", ip, "login", "FAIL"),
+			},
+			at: -1,
+		},
+		{
+			name: "users spread wider than the window",
+			events: []normalizer.Event{
+				login(0, "alice", ip, "login", "FAIL"),
+				login(100, "bob", ip, "login", "FAIL"),
+				login(200, "carol", ip, "login", "FAIL"),
+				login(400, "dave", ip, "login", "FAIL"),
+			},
+			at: -1,
+		},
+		{
+			name: "users from different IPs",
+			events: []normalizer.Event{
+				login(0, "alice", "10.0.0.1", "login", "FAIL"),
+				login(1, "bob", "10.0.0.2", "login", "FAIL"),
+				login(2, "carol", "10.0.0.3", "login", "FAIL"),
+				login(3, "dave", "10.0.0.4", "login", "FAIL"),
+			},
+			at: -1,
+		},
+		{
+			name: "successes and other actions do not count",
+			events: []normalizer.Event{
+				login(0, "alice", ip, "login", "FAIL"),
+				login(1, "bob", ip, "login", "SUCCESS"),
+				login(2, "carol", ip, "password_change", "FAIL"),
+				login(3, "dave", ip, "login", "FAIL"),
+				login(4, "", ip, "login", "FAIL"),
+				login(5, "erin", "", "login", "FAIL"),
+			},
+			at: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewPasswordSprayDetector(PasswordSprayConfig{Window: 5 * time.Minute, DistinctUsers: 4})
+			a, i := firstAlert(d, tt.events)
+			if i != tt.at {
+				t.Fatalf("alert at event %d, want %d", i, tt.at)
+			}
+			if i < 0 {
+				return
+			}
+			if a.RuleID != "PASSWORD_SPRAY" || a.Key != ip || a.Count != tt.count {
+				t.Errorf("alert %s key=%s count=%d, want key=%s count=%d", a.RuleID, a.Key, a.Count, ip, tt.count)
+			}
+			if got := a.Related[RelatedUsers]; !reflect.DeepEqual(got, tt.users) {
+				t.Errorf("related users %q, want %q", got, tt.users)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package detector
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// EvictionPolicy picks the keys to drop when a detector tracks MaxKeys.
+type EvictionPolicy int
+
+const (
+	EvictLRU         EvictionPolicy = iota // least recently updated key
+	EvictLowestCount                       // keys with the fewest events in window
+)
+
+func (p EvictionPolicy) String() string {
+	switch p {
+	case EvictLRU:
+		return "lru"
+	case EvictLowestCount:
+		return "lowest_count"
+	}
+	return "unknown"
+}
+
+func ParseEvictionPolicy(s string) (EvictionPolicy, error) {
+	switch s {
+	case "", "lru":
+		return EvictLRU, nil
+	case "lowest_count":
+		return EvictLowestCount, nil
+	}
+	return EvictLRU, fmt.Errorf("unknown eviction policy %q (want lru or lowest_count)", s)
+}
+
+// StateLimits bounds the per-key state of one detector.
+type StateLimits struct {
+	MaxKeys int // 0 = unlimited
+	Policy  EvictionPolicy
+}
+
+// StateStats is the size of a detector's state. Safe to read while the
+// detector runs on another goroutine.
+type StateStats struct {
+	Keys    int64  // keys tracked now
+	Events  int64  // events held in windows now
+	Idle    uint64 // keys dropped because their window had passed
+	Evicted uint64 // keys dropped to stay under MaxKeys
+}
+
+// StateReporter is implemented by detectors that keep per-key state.
+type StateReporter interface {
+	StateStats() StateStats
+}
+
+// lowestCountBatch is the share of keys dropped at once under
+// EvictLowestCount, so the sort is amortized over many inserts.
+const lowestCountBatch = 10 // percent
+
+// keyedState holds the windows of one detector by key. Empty windows are
+// deleted right away, keys whose newest event is older than the
+// watermark minus the window are swept periodically, and MaxKeys is
+// enforced on insert.
+type keyedState struct {
+	limits StateLimits
+
+	m         map[string]*list.Element // -> *stateEntry
+	lru       *list.List               // front = most recently updated
+	lastSweep time.Time
+
+	keys, events  atomic.Int64
+	idle, evicted atomic.Uint64
+}
+
+type stateEntry struct {
+	key string
+	w   window
+}
+
+func newKeyedState(limits StateLimits) *keyedState {
+	return &keyedState{
+		limits: limits,
+		m:      make(map[string]*list.Element),
+		lru:    list.New(),
+	}
+}
+
+func (s *keyedState) get(key string) window {
+	if el, ok := s.m[key]; ok {
+		return el.Value.(*stateEntry).w
+	}
+	return nil
+}
+
+// put stores w for key (deleting the key when w is empty) and marks it
+// most recently used.
+func (s *keyedState) put(key string, w window) {
+	if len(w) == 0 {
+		s.del(key)
+		return
+	}
+	if el, ok := s.m[key]; ok {
+		e := el.Value.(*stateEntry)
+		s.events.Add(int64(len(w) - len(e.w)))
+		e.w = w
+		s.lru.MoveToFront(el)
+		return
+	}
+	s.m[key] = s.lru.PushFront(&stateEntry{key: key, w: w})
+	s.keys.Add(1)
+	s.events.Add(int64(len(w)))
+	if s.limits.MaxKeys > 0 && len(s.m) > s.limits.MaxKeys {
+		s.shrink()
+	}
+}
+
+func (s *keyedState) del(key string) {
+	el, ok := s.m[key]
+	if !ok {
+		return
+	}
+	s.remove(el)
+}
+
+func (s *keyedState) remove(el *list.Element) {
+	e := el.Value.(*stateEntry)
+	s.lru.Remove(el)
+	delete(s.m, e.key)
+	s.keys.Add(-1)
+	s.events.Add(-int64(len(e.w)))
+}
+
+// shrink drops keys until the limit holds; the newest key is kept.
+func (s *keyedState) shrink() {
+	switch s.limits.Policy {
+	case EvictLowestCount:
+		n := len(s.m) * lowestCountBatch / 100
+		if over := len(s.m) - s.limits.MaxKeys; n < over {
+			n = over
+		}
+		// 오래된 키부터 모아 안정 정렬 → 같은 개수면 오래된 키가 먼저 제거됨
+		cands := make([]*list.Element, 0, len(s.m)-1)
+		for el := s.lru.Back(); el != nil && el != s.lru.Front(); el = el.Prev() {
+			cands = append(cands, el)
+		}
+		sort.SliceStable(cands, func(i, j int) bool {
+			return len(cands[i].Value.(*stateEntry).w) < len(cands[j].Value.(*stateEntry).w)
+		})
+		if n > len(cands) {
+			n = len(cands)
+		}
+		for _, el := range cands[:n] {
+			s.remove(el)
+			s.evicted.Add(1)
+		}
+	default:
+		for len(s.m) > s.limits.MaxKeys {
+			s.remove(s.lru.Back())
+			s.evicted.Add(1)
+		}
+	}
+}
+
+// maybeSweep drops idle keys once the watermark has moved a full window
+// since the last sweep, so the cost is amortized over that window.
+func (s *keyedState) maybeSweep(wm time.Time, width time.Duration) {
+	if wm.IsZero() || wm.Sub(s.lastSweep) < width {
+		return
+	}
+	s.lastSweep = wm
+	cutoff := wm.Add(-width)
+	for key, el := range s.m {
+		w := el.Value.(*stateEntry).w
+		if w[len(w)-1].TS.Before(cutoff) {
+			s.del(key)
+			s.idle.Add(1)
+		}
+	}
+}
+
+func (s *keyedState) stats() StateStats {
+	return StateStats{
+		Keys:    s.keys.Load(),
+		Events:  s.events.Load(),
+		Idle:    s.idle.Load(),
+		Evicted: s.evicted.Load(),
+	}
+}
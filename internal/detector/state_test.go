@@ -0,0 +1,129 @@
+package detector
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// keysOf lists the tracked keys, sorted.
+func keysOf(s *keyedState) []string {
+	var out []string
+	for k := range s.m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestKeyedStateEviction(t *testing.T) {
+	type put struct {
+		key    string
+		events int
+	}
+	tests := []struct {
+		name   string
+		limits StateLimits
+		puts   []put
+		keys   []string
+		stats  StateStats
+	}{
+		{
+			name:   "lru drops the least recently updated key",
+			limits: StateLimits{MaxKeys: 3, Policy: EvictLRU},
+			puts:   []put{{"a", 1}, {"b", 2}, {"c", 3}, {"a", 2}, {"d", 1}},
+			keys:   []string{"a", "c", "d"},
+			stats:  StateStats{Keys: 3, Events: 6, Evicted: 1},
+		},
+		{
+			name:   "lowest_count drops the smallest window",
+			limits: StateLimits{MaxKeys: 3, Policy: EvictLowestCount},
+			puts:   []put{{"a", 3}, {"b", 1}, {"c", 2}, {"d", 1}},
+			keys:   []string{"a", "c", "d"},
+			stats:  StateStats{Keys: 3, Events: 6, Evicted: 1},
+		},
+		{
+			name:   "lowest_count ties go to the least recently updated",
+			limits: StateLimits{MaxKeys: 3, Policy: EvictLowestCount},
+			puts:   []put{{"a", 1}, {"b", 1}, {"c", 2}, {"d", 1}},
+			keys:   []string{"b", "c", "d"},
+			stats:  StateStats{Keys: 3, Events: 4, Evicted: 1},
+		},
+		{
+			name:   "lowest_count keeps the newest key even if smallest",
+			limits: StateLimits{MaxKeys: 2, Policy: EvictLowestCount},
+			puts:   []put{{"a", 5}, {"b", 4}, {"c", 1}},
+			keys:   []string{"a", "c"},
+			stats:  StateStats{Keys: 2, Events: 6, Evicted: 1},
+		},
+		{
+			name:   "no limit",
+			limits: StateLimits{},
+			puts:   []put{{"a", 1}, {"b", 1}, {"c", 1}, {"d", 1}},
+			keys:   []string{"a", "b", "c", "d"},
+			stats:  StateStats{Keys: 4, Events: 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newKeyedState(tt.limits)
+			for i, p := range tt.puts {
+				secs := make([]int, p.events)
+				for j := range secs {
+					secs[j] = i
+				}
+				s.put(p.key, mkWindow(secs...))
+			}
+			if got := keysOf(s); !reflect.DeepEqual(got, tt.keys) {
+				t.Errorf("keys = %v, want %v", got, tt.keys)
+			}
+			if got := s.stats(); got != tt.stats {
+				t.Errorf("stats = %+v, want %+v", got, tt.stats)
+			}
+		})
+	}
+}
+
+func TestKeyedStateLowestCountBatch(t *testing.T) {
+	s := newKeyedState(StateLimits{MaxKeys: 20, Policy: EvictLowestCount})
+	for i := range 21 {
+		s.put(fmt.Sprintf("k%02d", i), mkWindow(make([]int, 1+i%3)...))
+	}
+	// 21개의 10% = 2개를 한 번에 제거 (정렬 비용 분산)
+	if got := s.stats(); got.Keys != 19 || got.Evicted != 2 {
+		t.Errorf("stats = %+v, want 19 keys, 2 evicted", got)
+	}
+	for _, k := range []string{"k00", "k03"} {
+		if _, ok := s.m[k]; ok {
+			t.Errorf("%s (1 event, oldest) still tracked", k)
+		}
+	}
+}
+
+func TestKeyedStateSweep(t *testing.T) {
+	s := newKeyedState(StateLimits{})
+	width := 10 * time.Second
+	s.put("a", mkWindow(0))
+	s.put("b", mkWindow(3, 15))
+
+	s.maybeSweep(at(5), width)
+	s.maybeSweep(at(12), width) // 마지막 스윕 후 윈도우만큼 지나지 않음: 건너뜀
+	if got := keysOf(s); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("keys after early sweeps = %v", got)
+	}
+	s.maybeSweep(at(20), width)
+	if got := keysOf(s); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("keys = %v, want [b]", got)
+	}
+	if got, want := s.stats(), (StateStats{Keys: 1, Events: 2, Idle: 1}); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+
+	// 빈 윈도우를 넣으면 키 삭제 (idle로 세지 않음)
+	s.put("b", nil)
+	if got, want := s.stats(), (StateStats{Idle: 1}); got != want {
+		t.Errorf("stats after emptying = %+v, want %+v", got, want)
+	}
+}
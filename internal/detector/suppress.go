@@ -0,0 +1,241 @@
+package detector
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+// SuppressConfig is the per-rule alert suppression.
+type SuppressConfig struct {
+	// Cooldown is how long after its last trigger an incident stays open.
+	// Triggers for the same key while it is open are merged into it
+	// instead of raising new alerts. 0 disables suppression: the detector
+	// resets the key after every alert (the old behaviour).
+	Cooldown time.Duration
+	// Summary emits a closing alert (Alert.Summary) when an incident with
+	// merged triggers ends.
+	Summary bool
+	// MaxOpen caps the open incidents (0 = unlimited), normally the
+	// detector's StateLimits.MaxKeys. At the cap, a new incident first
+	// closes the one quiet the longest early, with its summary.
+	MaxOpen int
+}
+
+// Flusher is implemented by detectors that hold alerts back. The engine
+// calls Flush with the watermark before each event, and with the zero
+// time at end of input to release everything still held.
+type Flusher interface {
+	Flush(wm time.Time) []Alert
+}
+
+// Resetter is implemented by detectors that keep a key's window after
+// alerting; Reset forgets the key, so the next alert needs a full new
+// window.
+type Resetter interface {
+	Reset(key string)
+}
+
+// Suppressor wraps a detector with per-key incidents: the first alert of
+// a key is passed on, repeat triggers within Cooldown only update the
+// incident (Count, LastSeen, Related), and once the watermark is past
+// LastSeen+Cooldown the incident is closed with a summary.
+type Suppressor struct {
+	inner Detector
+	cfg   SuppressConfig
+
+	open     map[string]*incident
+	byExpiry incidentHeap // open incidents, quiet the longest first
+	pending  []Alert      // summaries of closed incidents, not yet flushed
+}
+
+type incident struct {
+	key     string
+	alert   Alert
+	repeats int
+	index   int // in byExpiry
+}
+
+// incidentHeap orders incidents by expiry (LastSeen+Cooldown, the same
+// Cooldown for all), so Flush only looks at the ones that are due.
+type incidentHeap []*incident
+
+func (h incidentHeap) Len() int { return len(h) }
+func (h incidentHeap) Less(i, j int) bool {
+	if !h[i].alert.LastSeen.Equal(h[j].alert.LastSeen) {
+		return h[i].alert.LastSeen.Before(h[j].alert.LastSeen)
+	}
+	return h[i].key < h[j].key
+}
+func (h incidentHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+func (h *incidentHeap) Push(x any) {
+	inc := x.(*incident)
+	inc.index = len(*h)
+	*h = append(*h, inc)
+}
+func (h *incidentHeap) Pop() any {
+	old := *h
+	inc := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return inc
+}
+
+// Suppress wraps d. The result is Keyed when d is, so it can still be
+// sharded.
+func Suppress(d Detector, cfg SuppressConfig) Detector {
+	s := &Suppressor{inner: d, cfg: cfg, open: make(map[string]*incident)}
+	if _, ok := d.(Keyed); ok {
+		return keyedSuppressor{s}
+	}
+	return s
+}
+
+type keyedSuppressor struct{ *Suppressor }
+
+func (s keyedSuppressor) Key(ev normalizer.Event) string { return s.inner.(Keyed).Key(ev) }
+
+func (s *Suppressor) RuleID() string { return s.inner.RuleID() }
+
+func (s *Suppressor) Advance(wm time.Time) {
+	if w, ok := s.inner.(Watermarker); ok {
+		w.Advance(wm)
+	}
+}
+
+func (s *Suppressor) StateStats() StateStats {
+	if r, ok := s.inner.(StateReporter); ok {
+		return r.StateStats()
+	}
+	return StateStats{}
+}
+
+func (s *Suppressor) Process(ev normalizer.Event) (Alert, bool) {
+	a, ok := s.inner.Process(ev)
+	if !ok {
+		return Alert{}, false
+	}
+	if s.cfg.Cooldown <= 0 {
+		if r, ok := s.inner.(Resetter); ok {
+			r.Reset(a.Key)
+		}
+		return a, true
+	}
+
+	if inc, ok := s.open[a.Key]; ok {
+		if !a.LastSeen.After(inc.alert.LastSeen.Add(s.cfg.Cooldown)) {
+			inc.merge(a)
+			heap.Fix(&s.byExpiry, inc.index)
+			return Alert{}, false
+		}
+		s.close(inc)
+	}
+	if s.cfg.MaxOpen > 0 && len(s.open) >= s.cfg.MaxOpen {
+		// 상한 도달: 새 경고를 넣기 전에 가장 오래 조용했던 경고를 일찍 종료
+		// (요약은 다음 Flush에). 늦게 온 경고가 바로 종료되지 않도록 먼저 고름
+		s.close(s.byExpiry[0])
+	}
+	inc := &incident{key: a.Key, alert: a}
+	s.open[a.Key] = inc
+	heap.Push(&s.byExpiry, inc)
+	return a, true
+}
+
+// Flush closes the incidents quiet for Cooldown as of wm (all of them
+// for the zero time) and returns the pending summaries, oldest first.
+func (s *Suppressor) Flush(wm time.Time) []Alert {
+	for len(s.byExpiry) > 0 {
+		inc := s.byExpiry[0]
+		if !wm.IsZero() && !wm.After(inc.alert.LastSeen.Add(s.cfg.Cooldown)) {
+			break
+		}
+		s.close(inc)
+	}
+	if len(s.pending) == 0 {
+		return nil
+	}
+	out := s.pending
+	s.pending = nil
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].LastSeen.Equal(out[j].LastSeen) {
+			return out[i].LastSeen.Before(out[j].LastSeen)
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+func (s *Suppressor) close(inc *incident) {
+	heap.Remove(&s.byExpiry, inc.index)
+	delete(s.open, inc.key)
+	// 반복 탐지가 없었으면 첫 알림이 곧 전부라 요약을 생략
+	if !s.cfg.Summary || inc.repeats == 0 {
+		return
+	}
+	a := inc.alert
+	a.Summary = true
+	a.Suppressed = inc.repeats
+	s.pending = append(s.pending, a)
+}
+
+// merge folds a repeat trigger into the incident. A trigger entirely
+// after the incident adds its whole Count; one overlapping it (the window
+// kept after the last alert) adds its events newer than LastSeen, at
+// least one.
+func (inc *incident) merge(a Alert) {
+	inc.repeats++
+
+	if a.FirstSeen.After(inc.alert.LastSeen) {
+		inc.alert.Count += a.Count
+	} else {
+		n := 0
+		for _, ev := range a.Events {
+			if ev.TS.After(inc.alert.LastSeen) {
+				n++
+			}
+		}
+		inc.alert.Count += max(n, 1)
+	}
+
+	if a.FirstSeen.Before(inc.alert.FirstSeen) {
+		inc.alert.FirstSeen = a.FirstSeen
+	}
+	if a.LastSeen.After(inc.alert.LastSeen) {
+		inc.alert.LastSeen = a.LastSeen
+	}
+	if a.Severity > inc.alert.Severity {
+		inc.alert.Severity = a.Severity
+	}
+	// 증거 이벤트는 가장 최근 탐지 것으로 교체 (메모리 상한 유지)
+	inc.alert.Events = a.Events
+	inc.alert.Related = mergeRelated(inc.alert.Related, a.Related)
+}
+
+// mergeRelated appends the values of b missing from a, per kind.
+func mergeRelated(a, b map[string][]string) map[string][]string {
+	if len(b) == 0 {
+		return a
+	}
+	out := make(map[string][]string, len(a)+len(b))
+	for k, vs := range a {
+		out[k] = append([]string(nil), vs...)
+	}
+	for k, vs := range b {
+		seen := make(map[string]bool, len(out[k]))
+		for _, v := range out[k] {
+			seen[v] = true
+		}
+		for _, v := range vs {
+			if !seen[v] {
+				seen[v] = true
+				out[k] = append(out[k], v)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,230 @@
+package detector
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+// scripted raises the alerts queued in next, one per Process call.
+type scripted struct {
+	next   []Alert
+	resets []string
+}
+
+func (d *scripted) RuleID() string { return "TEST" }
+
+func (d *scripted) Process(normalizer.Event) (Alert, bool) {
+	if len(d.next) == 0 {
+		return Alert{}, false
+	}
+	a := d.next[0]
+	d.next = d.next[1:]
+	return a, true
+}
+
+func (d *scripted) Reset(key string) { d.resets = append(d.resets, key) }
+
+// trig is an alert for key over one event per second offset.
+func trig(key string, secs ...int) Alert {
+	a := Alert{RuleID: "TEST", Severity: SeverityMedium, Key: key, Count: len(secs),
+		FirstSeen: at(secs[0]), LastSeen: at(secs[len(secs)-1])}
+	for _, s := range secs {
+		a.Events = append(a.Events, normalizer.Event{TS: at(s), IP: key})
+	}
+	return a
+}
+
+// end is the flush at end of input (zero watermark).
+const end = -1
+
+type suppressStep struct {
+	trig  *Alert // nil: Flush(at(flush))
+	pass  bool
+	flush int
+	want  []string // summaries as "key count/suppressed first-last"
+}
+
+func sum(a Alert) string {
+	return fmt.Sprintf("%s %d/%d %d-%d", a.Key, a.Count, a.Suppressed,
+		a.FirstSeen.Sub(t0)/time.Second, a.LastSeen.Sub(t0)/time.Second)
+}
+
+func p(a Alert) *Alert { return &a }
+
+func TestSuppressor(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   SuppressConfig
+		steps []suppressStep
+	}{
+		{
+			name: "overlapping triggers add their new events",
+			cfg:  SuppressConfig{Cooldown: time.Minute, Summary: true},
+			steps: []suppressStep{
+				{trig: p(trig("A", 0, 1, 2, 3, 4)), pass: true},
+				{trig: p(trig("A", 1, 2, 3, 4, 5))},
+				{trig: p(trig("A", 2, 3, 4, 5, 6))},
+				{flush: 66},
+				{flush: 67, want: []string{"A 7/2 0-6"}},
+				{flush: end},
+			},
+		},
+		{
+			name: "disjoint triggers add their whole count",
+			cfg:  SuppressConfig{Cooldown: time.Minute, Summary: true},
+			steps: []suppressStep{
+				{trig: p(trig("A", 0, 1, 2, 3, 4)), pass: true},
+				{trig: p(trig("A", 30, 31, 32, 33, 34))},
+				{trig: p(trig("B", 40, 41)), pass: true},
+				{flush: end, want: []string{"A 10/1 0-34"}},
+			},
+		},
+		{
+			name: "trigger after the cooldown opens a new incident",
+			cfg:  SuppressConfig{Cooldown: time.Minute, Summary: true},
+			steps: []suppressStep{
+				{trig: p(trig("A", 0, 1, 2)), pass: true},
+				{trig: p(trig("A", 1, 2, 3))},
+				{trig: p(trig("A", 100, 101, 102)), pass: true},
+				{flush: 103, want: []string{"A 4/1 0-3"}},
+				{flush: end},
+			},
+		},
+		{
+			name: "summaries come out oldest first",
+			cfg:  SuppressConfig{Cooldown: time.Minute, Summary: true},
+			steps: []suppressStep{
+				{trig: p(trig("A", 0, 1, 2)), pass: true},
+				{trig: p(trig("B", 0, 1)), pass: true},
+				{trig: p(trig("A", 0, 1, 2, 3))},
+				{trig: p(trig("B", 0, 1, 2))},
+				{trig: p(trig("C", 0, 1, 2)), pass: true},
+				{trig: p(trig("C", 0, 1, 2, 3))},
+				{flush: end, want: []string{"B 3/1 0-2", "A 4/1 0-3", "C 4/1 0-3"}},
+			},
+		},
+		{
+			name: "no summary without Summary",
+			cfg:  SuppressConfig{Cooldown: time.Minute},
+			steps: []suppressStep{
+				{trig: p(trig("A", 0, 1, 2)), pass: true},
+				{trig: p(trig("A", 5, 6))},
+				{flush: end},
+			},
+		},
+		{
+			name: "MaxOpen closes the incident quiet the longest",
+			cfg:  SuppressConfig{Cooldown: time.Minute, Summary: true, MaxOpen: 2},
+			steps: []suppressStep{
+				{trig: p(trig("A", 0)), pass: true},
+				{trig: p(trig("B", 5, 6)), pass: true},
+				{trig: p(trig("B", 5, 6, 7))},
+				{trig: p(trig("A", 0, 8))},
+				{trig: p(trig("C", 9)), pass: true},  // B(7)이 A(8)보다 오래 조용함
+				{trig: p(trig("B", 10)), pass: true}, // 이번엔 A(8)가 종료
+				{flush: 10, want: []string{"B 3/1 5-7", "A 2/1 0-8"}},
+				{flush: end},
+			},
+		},
+		{
+			// 늦게 온 경고는 가장 오래 조용해 보여도 새로 연 경고라 닫히지 않음
+			name: "MaxOpen keeps a late alert's new incident",
+			cfg:  SuppressConfig{Cooldown: time.Minute, Summary: true, MaxOpen: 2},
+			steps: []suppressStep{
+				{trig: p(trig("A", 10)), pass: true},
+				{trig: p(trig("B", 20)), pass: true},
+				{trig: p(trig("B", 20, 21))},
+				{trig: p(trig("C", 5)), pass: true}, // A(10)가 종료
+				{trig: p(trig("C", 5, 6))},
+				{flush: end, want: []string{"C 2/1 5-6", "B 2/1 20-21"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &scripted{}
+			s := Suppress(inner, tt.cfg)
+			for i, st := range tt.steps {
+				if st.trig != nil {
+					inner.next = append(inner.next, *st.trig)
+					a, ok := s.Process(normalizer.Event{})
+					if ok != st.pass {
+						t.Fatalf("step %d: passed %v, want %v", i, ok, st.pass)
+					}
+					if ok && !reflect.DeepEqual(a, *st.trig) {
+						t.Errorf("step %d: passed %s, want the trigger unchanged", i, sum(a))
+					}
+					continue
+				}
+				var wm time.Time
+				if st.flush != end {
+					wm = at(st.flush)
+				}
+				var got []string
+				for _, a := range s.(Flusher).Flush(wm) {
+					if !a.Summary {
+						t.Errorf("step %d: flushed a non-summary alert %s", i, sum(a))
+					}
+					got = append(got, sum(a))
+				}
+				if !reflect.DeepEqual(got, st.want) {
+					t.Errorf("step %d: Flush(%d) = %q, want %q", i, st.flush, got, st.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSuppressorMergesSeverityAndRelated(t *testing.T) {
+	inner := &scripted{}
+	s := Suppress(inner, SuppressConfig{Cooldown: time.Minute, Summary: true})
+
+	first := trig("A", 0, 1)
+	first.Related = map[string][]string{RelatedUsers: {"alice"}}
+	repeat := trig("A", 5, 6)
+	repeat.Severity = SeverityHigh
+	repeat.Related = map[string][]string{RelatedUsers: {"bob", "alice"}, RelatedIPs: {"1.2.3.4"}}
+	inner.next = []Alert{first, repeat}
+
+	s.Process(normalizer.Event{})
+	s.Process(normalizer.Event{})
+	out := s.(Flusher).Flush(time.Time{})
+	if len(out) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(out))
+	}
+	a := out[0]
+	if a.Severity != SeverityHigh {
+		t.Errorf("Severity = %v, want %v", a.Severity, SeverityHigh)
+	}
+	want := map[string][]string{RelatedUsers: {"alice", "bob"}, RelatedIPs: {"1.2.3.4"}}
+	if !reflect.DeepEqual(a.Related, want) {
+		t.Errorf("Related = %v, want %v", a.Related, want)
+	}
+	if !reflect.DeepEqual(a.Events, repeat.Events) {
+		t.Errorf("Events = %v, want the latest trigger's", a.Events)
+	}
+	// 첫 알림의 Related는 그대로
+	if !reflect.DeepEqual(first.Related, map[string][]string{RelatedUsers: {"alice"}}) {
+		t.Errorf("first alert's Related changed to %v", first.Related)
+	}
+}
+
+func TestSuppressorNoCooldown(t *testing.T) {
+	inner := &scripted{next: []Alert{trig("A", 0, 1), trig("A", 1, 2), trig("B", 2)}}
+	s := Suppress(inner, SuppressConfig{Summary: true})
+	for i := range 3 {
+		if _, ok := s.Process(normalizer.Event{}); !ok {
+			t.Errorf("trigger %d suppressed with Cooldown 0", i)
+		}
+	}
+	if want := []string{"A", "A", "B"}; !reflect.DeepEqual(inner.resets, want) {
+		t.Errorf("resets = %q, want %q", inner.resets, want)
+	}
+	if out := s.(Flusher).Flush(time.Time{}); out != nil {
+		t.Errorf("Flush = %v, want nil", out)
+	}
+}
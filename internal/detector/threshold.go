@@ -0,0 +1,97 @@
+package detector
+
+import (
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+// ThresholdConfig describes the classic sliding-window rule:
+// "Threshold matching events for one key within Window".
+type ThresholdConfig struct {
+	RuleID    string
+	Severity  Severity
+	Window    time.Duration
+	Threshold int
+
+	// Match selects the events the rule counts.
+	Match func(ev normalizer.Event) bool
+	// GroupBy returns the entity key; "" means the event is skipped.
+	GroupBy func(ev normalizer.Event) string
+
+	// Limits bounds the number of keys tracked.
+	Limits StateLimits
+}
+
+type ThresholdDetector struct {
+	cfg   ThresholdConfig
+	clock eventClock
+
+	// key -> matching events (sliding window, time-sorted)
+	events *keyedState
+}
+
+func NewThresholdDetector(cfg ThresholdConfig) *ThresholdDetector {
+	return &ThresholdDetector{
+		cfg:    cfg,
+		events: newKeyedState(cfg.Limits),
+	}
+}
+
+func (d *ThresholdDetector) RuleID() string { return d.cfg.RuleID }
+
+func (d *ThresholdDetector) Advance(wm time.Time) {
+	d.clock.Advance(wm)
+	d.events.maybeSweep(d.clock.wm, d.cfg.Window)
+}
+
+func (d *ThresholdDetector) StateStats() StateStats { return d.events.stats() }
+
+func (d *ThresholdDetector) Key(ev normalizer.Event) string {
+	if !d.cfg.Match(ev) {
+		return ""
+	}
+	return d.cfg.GroupBy(ev)
+}
+
+func (d *ThresholdDetector) Process(ev normalizer.Event) (Alert, bool) {
+	key := d.Key(ev)
+	if key == "" {
+		return Alert{}, false
+	}
+
+	wm := d.clock.watermark(ev.TS)
+	d.events.maybeSweep(wm, d.cfg.Window)
+
+	// 1) insert current event (in time order; it may be late)
+	// 2) evict events no window reachable from the watermark can use
+	list := d.events.get(key).insert(ev).evict(wm.Add(-d.cfg.Window))
+
+	// 3) threshold check on a window containing the event
+	run := list.span(ev.TS, d.cfg.Window, func(run window) bool {
+		return len(run) >= d.cfg.Threshold
+	})
+	if run == nil {
+		d.events.put(key, list)
+		return Alert{}, false
+	}
+
+	a := Alert{
+		RuleID:    d.cfg.RuleID,
+		Severity:  d.cfg.Severity,
+		Key:       key,
+		Count:     len(run),
+		Window:    d.cfg.Window,
+		FirstSeen: run[0].TS,
+		LastSeen:  run[len(run)-1].TS,
+		Events:    append([]normalizer.Event(nil), run...),
+	}
+
+	// 윈도우는 유지: 이후 이벤트도 계속 탐지되고, 도배 방지는 Suppressor가
+	// 쿨다운으로 하나의 진행 중 경고에 합친다 (쿨다운 0이면 Reset으로 리셋)
+	d.events.put(key, list)
+
+	return a, true
+}
+
+func (d *ThresholdDetector) Reset(key string) { d.events.del(key) }
@@ -0,0 +1,111 @@
+package detector
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+// Clock tracks event time over one event stream. The watermark is the
+// newest timestamp seen minus Lateness; events older than the watermark
+// are late and are rejected (and counted) instead of being fed to the
+// detectors, where they would evict valid history or count stale events.
+type Clock struct {
+	Lateness time.Duration
+
+	max  time.Time
+	late atomic.Uint64
+}
+
+// Observe records ts and reports whether the event is on time.
+func (c *Clock) Observe(ts time.Time) bool {
+	if !c.max.IsZero() && ts.Before(c.Watermark()) {
+		c.late.Add(1)
+		return false
+	}
+	if ts.After(c.max) {
+		c.max = ts
+	}
+	return true
+}
+
+// Watermark is the time before which no more events are accepted
+// (zero before the first event).
+func (c *Clock) Watermark() time.Time {
+	if c.max.IsZero() {
+		return time.Time{}
+	}
+	return c.max.Add(-c.Lateness)
+}
+
+// Late is the number of events rejected as too late. Safe to read while
+// another goroutine observes events.
+func (c *Clock) Late() uint64 { return c.late.Load() }
+
+// Watermarker is implemented by detectors with time windows. The engine
+// calls Advance with its Clock's watermark before each event; windows
+// then keep everything the watermark still allows, so a late (but not
+// too late) event lands in the right window.
+type Watermarker interface {
+	Advance(wm time.Time)
+}
+
+// eventClock is the watermark as seen by one detector. Until Advance is
+// called (detector used on its own), it simply follows the newest event.
+type eventClock struct {
+	wm     time.Time
+	driven bool
+}
+
+func (c *eventClock) Advance(wm time.Time) {
+	c.driven = true
+	if wm.After(c.wm) {
+		c.wm = wm
+	}
+}
+
+// watermark returns the current watermark, given an incoming event at ts.
+func (c *eventClock) watermark(ts time.Time) time.Time {
+	if !c.driven && ts.After(c.wm) {
+		c.wm = ts
+	}
+	return c.wm
+}
+
+// window is the event list of one key, sorted by time.
+type window []normalizer.Event
+
+// insert adds ev after any events with the same timestamp.
+func (w window) insert(ev normalizer.Event) window {
+	i := sort.Search(len(w), func(i int) bool { return w[i].TS.After(ev.TS) })
+	w = append(w, normalizer.Event{})
+	copy(w[i+1:], w[i:])
+	w[i] = ev
+	return w
+}
+
+// evict drops the events older than cutoff.
+func (w window) evict(cutoff time.Time) window {
+	i := sort.Search(len(w), func(i int) bool { return !w[i].TS.Before(cutoff) })
+	if i == 0 {
+		return w
+	}
+	return append(w[:0], w[i:]...)
+}
+
+// span returns the earliest run of events spanning at most width that
+// includes time ts and satisfies ok, or nil. For in-order events (ts is
+// the newest) this is the classic window [ts-width, ts].
+func (w window) span(ts time.Time, width time.Duration, ok func(run window) bool) window {
+	lo := sort.Search(len(w), func(i int) bool { return !w[i].TS.Before(ts.Add(-width)) })
+	for i := lo; i < len(w) && !w[i].TS.After(ts); i++ {
+		limit := w[i].TS.Add(width)
+		end := sort.Search(len(w), func(j int) bool { return w[j].TS.After(limit) })
+		if run := w[i:end]; ok(run) {
+			return run
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package detector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+// mkWindow builds a window with one event per second offset, in order.
+func mkWindow(secs ...int) window {
+	var w window
+	for _, s := range secs {
+		w = append(w, normalizer.Event{TS: at(s)})
+	}
+	return w
+}
+
+// secs is the inverse of mkWindow (nil for a nil window).
+func secs(w window) []int {
+	if w == nil {
+		return nil
+	}
+	out := make([]int, len(w))
+	for i, ev := range w {
+		out[i] = int(ev.TS.Sub(t0) / time.Second)
+	}
+	return out
+}
+
+func TestWindowInsert(t *testing.T) {
+	var w window
+	for i, s := range []int{5, 1, 9, 5, 0, 7} {
+		w = w.insert(normalizer.Event{TS: at(s), User: string(rune('a' + i))})
+	}
+	if got, want := secs(w), []int{0, 1, 5, 5, 7, 9}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("insert order %v, want %v", got, want)
+	}
+	// 같은 시각이면 나중에 넣은 이벤트가 뒤에
+	if w[2].User != "a" || w[3].User != "d" {
+		t.Errorf("ties: %q then %q, want a then d", w[2].User, w[3].User)
+	}
+}
+
+func TestWindowEvict(t *testing.T) {
+	tests := []struct {
+		cutoff int
+		want   []int
+	}{
+		{-1, []int{1, 3, 5}},
+		{1, []int{1, 3, 5}},
+		{2, []int{3, 5}},
+		{5, []int{5}},
+		{6, []int{}},
+	}
+	for _, tt := range tests {
+		if got := secs(mkWindow(1, 3, 5).evict(at(tt.cutoff))); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("evict(%d) = %v, want %v", tt.cutoff, got, tt.want)
+		}
+	}
+}
+
+func TestWindowSpan(t *testing.T) {
+	tests := []struct {
+		name  string
+		w     []int
+		ts    int
+		width int
+		n     int // run must hold at least n events
+		want  []int
+	}{
+		{"in order, newest event", []int{0, 4, 8, 10}, 10, 10, 4, []int{0, 4, 8, 10}},
+		{"in order, too spread", []int{0, 4, 8, 11}, 11, 10, 4, nil},
+		{"in order, older events ignored", []int{0, 4, 8, 11}, 11, 10, 3, []int{4, 8, 11}},
+		{"late event completes an earlier window", []int{0, 3, 5, 30}, 3, 10, 3, []int{0, 3, 5}},
+		{"late event starts the window", []int{10, 12, 14, 40}, 10, 5, 3, []int{10, 12, 14}},
+		{"run must include ts", []int{0, 1, 2, 30}, 30, 10, 3, nil},
+		{"earliest run wins", []int{0, 5, 10, 15}, 10, 5, 2, []int{5, 10}},
+		{"ties count together", []int{7, 7, 7}, 7, 0, 3, []int{7, 7, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := mkWindow(tt.w...).span(at(tt.ts), time.Duration(tt.width)*time.Second,
+				func(run window) bool { return len(run) >= tt.n })
+			if got := secs(run); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("span = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClock(t *testing.T) {
+	c := Clock{Lateness: 30 * time.Second}
+	if !c.Watermark().IsZero() {
+		t.Fatalf("watermark before any event = %v", c.Watermark())
+	}
+	steps := []struct {
+		ts     int
+		onTime bool
+		wm     int
+	}{
+		{100, true, 70},
+		{90, true, 70},  // 늦었지만 허용 범위 안
+		{70, true, 70},  // 워터마크와 같으면 허용
+		{69, false, 70}, // 너무 늦음
+		{130, true, 100},
+		{99, false, 100},
+		{120, true, 100},
+	}
+	for i, s := range steps {
+		if got := c.Observe(at(s.ts)); got != s.onTime {
+			t.Errorf("step %d: Observe(%d) = %v, want %v", i, s.ts, got, s.onTime)
+		}
+		if !c.Watermark().Equal(at(s.wm)) {
+			t.Errorf("step %d: Watermark = %v, want %v", i, c.Watermark(), at(s.wm))
+		}
+	}
+	if c.Late() != 2 {
+		t.Errorf("Late = %d, want 2", c.Late())
+	}
+}
+
+func TestThresholdLateEvent(t *testing.T) {
+	d := NewThresholdDetector(ThresholdConfig{
+		RuleID:    "TEST",
+		Window:    10 * time.Second,
+		Threshold: 3,
+		Match:     func(normalizer.Event) bool { return true },
+		GroupBy:   func(ev normalizer.Event) string { return ev.IP },
+	})
+	c := Clock{Lateness: 30 * time.Second}
+
+	var alerts []Alert
+	for _, s := range []int{0, 20, 8, 9} {
+		ev := normalizer.Event{TS: at(s), IP: "1.2.3.4"}
+		if !c.Observe(ev.TS) {
+			t.Fatalf("event at %d dropped as late", s)
+		}
+		d.Advance(c.Watermark())
+		if a, ok := d.Process(ev); ok {
+			alerts = append(alerts, a)
+		}
+	}
+	// 20초 이벤트 뒤에 온 8, 9초 이벤트가 0초와 같은 윈도우로 묶여야 함
+	if len(alerts) != 1 {
+		t.Fatalf("got %d alerts, want 1", len(alerts))
+	}
+	a := alerts[0]
+	if a.Count != 3 || !a.FirstSeen.Equal(at(0)) || !a.LastSeen.Equal(at(9)) {
+		t.Errorf("alert count=%d first=%v last=%v, want 3 in [0s, 9s]", a.Count, a.FirstSeen, a.LastSeen)
+	}
+}
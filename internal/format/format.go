@@ -0,0 +1,251 @@
+// Package format turns detector.Alert values into human-readable text
+// (Korean) and into the report.json record shared by the CLI and TUIs.
+package format
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/normalizer"
+)
+
+// RuleInfo is the display text for one rule ID.
+type RuleInfo struct {
+	Title       string
+	Description string
+	KeyLabel    string // "IP", "사용자" ...
+	CountLabel  string // "실패 횟수", "시도 횟수" ...
+
+	// ShowEvents lists every contributing event in Message
+	// (sequence rules, where the order is the evidence).
+	ShowEvents bool
+}
+
+// mu guards rules: rule engines register while TUIs render.
+var mu sync.RWMutex
+
+var rules = map[string]RuleInfo{
+	"BRUTE_FORCE_LOGIN": {
+		Title:       "로그인 브루트포스 의심",
+		Description: "동일 IP에서 짧은 시간에 로그인 실패가 반복되었습니다.",
+		KeyLabel:    "IP",
+		CountLabel:  "실패 횟수",
+	},
+	"SSH_BRUTE_FORCE": {
+		Title:       "SSH 브루트포스 공격 의심",
+		Description: "동일 IP에서 SSH 인증 실패가 짧은 시간에 반복되었습니다.",
+		KeyLabel:    "IP",
+		CountLabel:  "실패 횟수",
+	},
+	"WEB_ENUMERATION": {
+		Title:       "웹 경로 스캐닝(열거) 공격 의심",
+		Description: "관리자/환경 파일 등 민감 경로에 대한 접근이 반복되었습니다.",
+		KeyLabel:    "IP",
+		CountLabel:  "시도 횟수",
+	},
+	"ACCOUNT_TAKEOVER": {
+		Title:       "계정 탈취(ATO) 의심",
+		Description: "로그인 실패가 반복된 뒤 로그인에 성공했고, 이어서 비밀번호 변경 등 민감한 작업이 수행되었습니다.",
+		KeyLabel:    "사용자",
+		CountLabel:  "실패 횟수",
+		ShowEvents:  true,
+	},
+	"PASSWORD_SPRAY": {
+		Title:       "패스워드 스프레이 공격 의심",
+		Description: "동일 IP에서 여러 계정에 대한 로그인 실패가 발생했습니다.",
+		KeyLabel:    "IP",
+		CountLabel:  "실패 횟수",
+	},
+	"DISTRIBUTED_BRUTE_FORCE": {
+		Title:       "분산 브루트포스 공격 의심",
+		Description: "하나의 계정에 대해 여러 IP에서 로그인 실패가 반복되었습니다(봇넷 등 IP 우회 의심).",
+		KeyLabel:    "사용자",
+		CountLabel:  "실패 횟수",
+	},
+}
+
+// relatedLabels are the Message labels for detector.Alert.Related keys.
+var relatedLabels = []struct{ key, label string }{
+	{detector.RelatedUsers, "대상 계정"},
+	{detector.RelatedIPs, "주요 출발지 IP"},
+	{detector.RelatedSubnets, "주요 서브넷"},
+}
+
+// Register adds or replaces the display text for ruleID. Rule files carry
+// their own title/description and register them when compiled.
+// Call it during startup, before alerts are rendered.
+func Register(ruleID string, info RuleInfo) {
+	mu.Lock()
+	defer mu.Unlock()
+	rules[ruleID] = info
+}
+
+// Rule returns the display info for ruleID, falling back to the ID itself.
+func Rule(ruleID string) RuleInfo {
+	mu.RLock()
+	defer mu.RUnlock()
+	if info, ok := rules[ruleID]; ok {
+		return info
+	}
+	return RuleInfo{Title: ruleID, KeyLabel: "대상", CountLabel: "횟수"}
+}
+
+func SeverityKR(sev detector.Severity) string {
+	switch sev {
+	case detector.SeverityCritical:
+		return "치명"
+	case detector.SeverityHigh:
+		return "높음"
+	case detector.SeverityMedium:
+		return "중간"
+	case detector.SeverityLow:
+		return "낮음"
+	default:
+		return sev.String()
+	}
+}
+
+func icon(sev detector.Severity) string {
+	if sev >= detector.SeverityHigh {
+		return "🚨"
+	}
+	return "⚠️"
+}
+
+// Title is the one-line headline, e.g. "로그인 브루트포스 의심".
+func Title(a detector.Alert) string {
+	return Rule(a.RuleID).Title
+}
+
+// Message renders the multi-line Korean alert text printed by the CLI.
+func Message(a detector.Alert) string {
+	info := Rule(a.RuleID)
+
+	var b strings.Builder
+	if a.Summary {
+		// 쿨다운 동안 합쳐진 공격이 끝났을 때의 종료 요약
+		fmt.Fprintf(&b, "%s [종료 요약][%s] %s\n", icon(a.Severity), SeverityKR(a.Severity), info.Title)
+		fmt.Fprintf(&b, "- %s: %s\n", info.KeyLabel, a.Key)
+		fmt.Fprintf(&b, "- %s: 누적 %d회 (반복 탐지 %d회 억제)\n", info.CountLabel, a.Count, a.Suppressed)
+		fmt.Fprintf(&b, "- 지속 시간: %s\n", a.LastSeen.Sub(a.FirstSeen))
+	} else {
+		fmt.Fprintf(&b, "%s [경고][%s] %s\n", icon(a.Severity), SeverityKR(a.Severity), info.Title)
+		fmt.Fprintf(&b, "- %s: %s\n", info.KeyLabel, a.Key)
+		fmt.Fprintf(&b, "- %s: %d회 (%d초 윈도우)\n", info.CountLabel, a.Count, int(a.Window.Seconds()))
+	}
+	fmt.Fprintf(&b, "- 최초 시각: %s\n", a.FirstSeen.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- 마지막 시각: %s", a.LastSeen.UTC().Format(time.RFC3339))
+	for _, rl := range relatedLabels {
+		if vals := a.Related[rl.key]; len(vals) > 0 {
+			fmt.Fprintf(&b, "\n- %s(%d): %s", rl.label, len(vals), strings.Join(vals, ", "))
+		}
+	}
+	if info.Description != "" {
+		fmt.Fprintf(&b, "\n- 설명: %s", info.Description)
+	}
+	if info.ShowEvents && len(a.Events) > 0 {
+		b.WriteString("\n- 이벤트 흐름:")
+		for _, e := range a.Events {
+			fmt.Fprintf(&b, "\n  · %s %s %s (ip=%s)",
+				e.TS.UTC().Format(time.RFC3339), e.Action, e.Status, e.IP)
+		}
+	}
+	return b.String()
+}
+
+// Record is one entry of report.json.
+type Record struct {
+	TS       time.Time `json:"ts"`
+	Severity string    `json:"severity"` // 낮음/중간/높음/치명
+	Title    string    `json:"title"`
+	Message  string    `json:"message"` // 한 줄 설명 (룰 description, 없으면 제목)
+	// Text is the full multi-line alert as the CLI prints it (Message()).
+	Text string `json:"text,omitempty"`
+
+	IP      string `json:"ip,omitempty"`
+	RuleID  string `json:"rule_id,omitempty"`
+	Service string `json:"service,omitempty"`
+
+	Key       string        `json:"key,omitempty"`
+	Count     int           `json:"count,omitempty"`
+	Window    time.Duration `json:"window_ns,omitempty"`
+	FirstSeen time.Time     `json:"first_seen,omitzero"`
+	LastSeen  time.Time     `json:"last_seen,omitzero"`
+
+	Related map[string][]string `json:"related,omitempty"`
+
+	// Summary marks the closing record of a suppressed incident;
+	// Suppressed is the number of repeat triggers merged into it.
+	Summary    bool `json:"summary,omitempty"`
+	Suppressed int  `json:"suppressed,omitempty"`
+
+	// Events are the contributing events; only filled on request.
+	Events []EventRecord `json:"events,omitempty"`
+}
+
+// EventRecord is the machine-readable form of a normalized event.
+type EventRecord struct {
+	TS      time.Time         `json:"ts"`
+	Service string            `json:"service"`
+	Action  string            `json:"action,omitempty"`
+	User    string            `json:"user,omitempty"`
+	IP      string            `json:"ip,omitempty"`
+	Status  string            `json:"status,omitempty"`
+	Path    string            `json:"path,omitempty"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+	Raw     string            `json:"raw,omitempty"`
+}
+
+func ToEventRecord(ev normalizer.Event) EventRecord {
+	return EventRecord{
+		TS:      ev.TS,
+		Service: ev.Service,
+		Action:  ev.Action,
+		User:    ev.User,
+		IP:      ev.IP,
+		Status:  ev.Status,
+		Path:    ev.Path,
+		Attrs:   ev.Attrs,
+		Raw:     ev.RawLine,
+	}
+}
+
+// summary is the one-line report.json message: the rule description,
+// or its title when it has none.
+func summary(a detector.Alert) string {
+	if info := Rule(a.RuleID); info.Description != "" {
+		return info.Description
+	}
+	return Title(a)
+}
+
+// ToRecord flattens an alert into a report.json record.
+// TS is the time of the last contributing event.
+func ToRecord(a detector.Alert) Record {
+	r := Record{
+		TS:        a.LastSeen,
+		Severity:  SeverityKR(a.Severity),
+		Title:     Title(a),
+		Message:   summary(a),
+		Text:      Message(a),
+		RuleID:    a.RuleID,
+		Key:       a.Key,
+		Count:     a.Count,
+		Window:    a.Window,
+		FirstSeen: a.FirstSeen,
+		LastSeen:  a.LastSeen,
+		Related:   a.Related,
+
+		Summary:    a.Summary,
+		Suppressed: a.Suppressed,
+	}
+	if n := len(a.Events); n > 0 {
+		last := a.Events[n-1]
+		r.IP = last.IP
+		r.Service = last.Service
+	}
+	return r
+}
@@ -0,0 +1,169 @@
+// Package ingest reads log sources and merges them into one event stream
+// ordered by timestamp.
+package ingest
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+
+	"go-logshield/internal/normalizer"
+)
+
+// Item is one line of a source: a parsed event, or a parse/read error.
+type Item struct {
+	Source string
+	Line   string
+	Event  normalizer.Event
+	Err    error
+}
+
+// Stream yields the items of one source in file order; ok is false at
+// the end.
+type Stream interface {
+	Next() (it Item, ok bool)
+}
+
+// ReadError is the Item.Err of a source that failed to read; the
+// stream ends after it. Other errors are parse errors of Item.Line.
+type ReadError struct {
+	Source string
+	Err    error
+}
+
+func (e *ReadError) Error() string { return fmt.Sprintf("%s: %v", e.Source, e.Err) }
+func (e *ReadError) Unwrap() error { return e.Err }
+
+// LineStream parses the lines of r with a registry source.
+type LineStream struct {
+	src *normalizer.Source
+	sc  *bufio.Scanner
+	err error
+}
+
+func NewLineStream(r io.Reader, src *normalizer.Source) *LineStream {
+	return &LineStream{src: src, sc: bufio.NewScanner(r)}
+}
+
+func (s *LineStream) Next() (Item, bool) {
+	if s.err != nil {
+		return Item{}, false
+	}
+	if !s.sc.Scan() {
+		if err := s.sc.Err(); err != nil {
+			s.err = err
+			return Item{Source: s.src.Name(), Err: &ReadError{Source: s.src.Name(), Err: err}}, true
+		}
+		s.err = io.EOF
+		return Item{}, false
+	}
+	line := s.sc.Text()
+	ev, err := s.src.Parse(line)
+	return Item{Source: s.src.Name(), Line: line, Event: ev, Err: err}, true
+}
+
+// Concat yields the streams one after another (no reordering).
+func Concat(streams ...Stream) Stream { return &concat{streams: streams} }
+
+type concat struct{ streams []Stream }
+
+func (c *concat) Next() (Item, bool) {
+	for len(c.streams) > 0 {
+		if it, ok := c.streams[0].Next(); ok {
+			return it, true
+		}
+		c.streams = c.streams[1:]
+	}
+	return Item{}, false
+}
+
+// DefaultLookahead is the per-stream reorder buffer used by cmd/logshield.
+const DefaultLookahead = 64
+
+// Merger is a k-way merge of streams by event time. It buffers at most
+// Lookahead events per stream, so lines up to Lookahead-1 positions out
+// of order within one source still come out sorted; with Lookahead 1 it
+// is a plain merge of sorted inputs. Errors carry no timestamp and are
+// passed through as soon as they are read.
+type Merger struct {
+	streams   []Stream
+	lookahead int
+	buffered  []int
+	done      []bool
+	h         itemHeap
+	seq       uint64
+	refill    []int // streams to top up before the next pop
+}
+
+func NewMerger(lookahead int, streams ...Stream) *Merger {
+	if lookahead < 1 {
+		lookahead = 1
+	}
+	m := &Merger{
+		streams:   streams,
+		lookahead: lookahead,
+		buffered:  make([]int, len(streams)),
+		done:      make([]bool, len(streams)),
+	}
+	for i := range streams {
+		m.refill = append(m.refill, i)
+	}
+	return m
+}
+
+// Next returns the next item in time order; ok is false when every
+// stream is exhausted.
+func (m *Merger) Next() (Item, bool) {
+	for len(m.refill) > 0 {
+		i := m.refill[len(m.refill)-1]
+		for !m.done[i] && m.buffered[i] < m.lookahead {
+			it, ok := m.streams[i].Next()
+			if !ok {
+				m.done[i] = true
+				break
+			}
+			if it.Err != nil {
+				return it, true
+			}
+			m.seq++
+			heap.Push(&m.h, heapItem{Item: it, stream: i, seq: m.seq})
+			m.buffered[i]++
+		}
+		m.refill = m.refill[:len(m.refill)-1]
+	}
+
+	if m.h.Len() == 0 {
+		return Item{}, false
+	}
+	hi := heap.Pop(&m.h).(heapItem)
+	m.buffered[hi.stream]--
+	m.refill = append(m.refill, hi.stream)
+	return hi.Item, true
+}
+
+type heapItem struct {
+	Item
+	stream int
+	seq    uint64
+}
+
+// itemHeap orders by event time, then by read order (stable for ties).
+type itemHeap []heapItem
+
+func (h itemHeap) Len() int { return len(h) }
+func (h itemHeap) Less(i, j int) bool {
+	if !h[i].Event.TS.Equal(h[j].Event.TS) {
+		return h[i].Event.TS.Before(h[j].Event.TS)
+	}
+	return h[i].seq < h[j].seq
+}
+func (h itemHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *itemHeap) Push(x any)   { *h = append(*h, x.(heapItem)) }
+func (h *itemHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
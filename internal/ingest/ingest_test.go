@@ -0,0 +1,144 @@
+package ingest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-logshield/internal/normalizer"
+)
+
+var t0 = time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+
+// sliceStream yields one item per entry: "name@sec" is an event at t0+sec
+// with Line name, "!name" is a parse error.
+type sliceStream []string
+
+func (s *sliceStream) Next() (Item, bool) {
+	if len(*s) == 0 {
+		return Item{}, false
+	}
+	e := (*s)[0]
+	*s = (*s)[1:]
+	if name, ok := strings.CutPrefix(e, "!"); ok {
+		return Item{Line: name, Err: errors.New("bad line")}, true
+	}
+	name, sec, _ := strings.Cut(e, "@")
+	d, _ := time.ParseDuration(sec + "s")
+	return Item{Line: name, Event: normalizer.Event{TS: t0.Add(d)}}, true
+}
+
+func stream(entries ...string) *sliceStream {
+	s := sliceStream(entries)
+	return &s
+}
+
+// drain returns the Line of every item, in order.
+func drain(s Stream) []string {
+	var out []string
+	for {
+		it, ok := s.Next()
+		if !ok {
+			return out
+		}
+		out = append(out, it.Line)
+	}
+}
+
+func TestMerger(t *testing.T) {
+	tests := []struct {
+		name      string
+		lookahead int
+		streams   []*sliceStream
+		want      []string
+	}{
+		{
+			name:      "sorted inputs interleave",
+			lookahead: 1,
+			streams:   []*sliceStream{stream("a1@1", "a2@3", "a3@5"), stream("b1@2", "b2@4", "b3@6")},
+			want:      []string{"a1", "b1", "a2", "b2", "a3", "b3"},
+		},
+		{
+			name:      "ties keep file order",
+			lookahead: 3,
+			streams:   []*sliceStream{stream("x2@2", "x1@1", "y1@1", "z1@1")},
+			want:      []string{"x1", "y1", "z1", "x2"},
+		},
+		{
+			name:      "disorder within lookahead is sorted",
+			lookahead: 3,
+			streams:   []*sliceStream{stream("x1@1", "x4@4", "x5@5", "x2@2", "x6@6"), stream("y3@3")},
+			want:      []string{"x1", "x2", "y3", "x4", "x5", "x6"},
+		},
+		{
+			name:      "disorder beyond lookahead stays late",
+			lookahead: 2,
+			streams:   []*sliceStream{stream("x1@1", "x4@4", "x5@5", "x2@2", "x6@6")},
+			want:      []string{"x1", "x4", "x2", "x5", "x6"},
+		},
+		{
+			name:      "lookahead 1 is a plain merge",
+			lookahead: 0,
+			streams:   []*sliceStream{stream("x3@3", "x1@1", "x2@2")},
+			want:      []string{"x3", "x1", "x2"},
+		},
+		{
+			name:      "errors pass through when read",
+			lookahead: 1,
+			streams:   []*sliceStream{stream("a1@1", "!e", "a3@3"), stream("b0@0", "b2@2")},
+			want:      []string{"b0", "a1", "e", "b2", "a3"},
+		},
+		{
+			name:      "empty streams",
+			lookahead: 4,
+			streams:   []*sliceStream{stream(), stream("a1@1"), stream()},
+			want:      []string{"a1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams := make([]Stream, len(tt.streams))
+			for i, s := range tt.streams {
+				streams[i] = s
+			}
+			if got := drain(NewMerger(tt.lookahead, streams...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConcat(t *testing.T) {
+	got := drain(Concat(stream("a2@2", "a1@1"), stream(), stream("b0@0")))
+	if want := []string{"a2", "a1", "b0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLineStream(t *testing.T) {
+	src := normalizer.NewRegistry().Source("app.log")
+	s := NewLineStream(strings.NewReader(
+		"2026-02-01T12:00:01Z service=auth user=alice\nnot a log line\n2026-02-01T12:00:02Z service=auth user=bob\n"), src)
+
+	var users []string
+	var errs int
+	for {
+		it, ok := s.Next()
+		if !ok {
+			break
+		}
+		if it.Source != "app.log" {
+			t.Errorf("Source = %q", it.Source)
+		}
+		if it.Err != nil {
+			errs++
+			continue
+		}
+		users = append(users, it.Event.User)
+	}
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(users, want) || errs != 1 {
+		t.Errorf("users %q, %d errors; want %q, 1 error", users, errs, want)
+	}
+}
@@ -0,0 +1,220 @@
+package normalizer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Predefined nginx log_format strings. Apache's "combined" and "common"
+// LogFormat produce the same lines.
+const (
+	FormatCombined = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent"`
+	FormatCommon   = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent`
+)
+
+// Attribute keys filled by the access log parser (plus method and ua).
+const (
+	AttrQuery        = "query"
+	AttrBytes        = "bytes"
+	AttrReferer      = "referer"
+	AttrProtocol     = "protocol"
+	AttrResponseTime = "request_time" // seconds, as logged
+)
+
+// Query is the request query string without the leading "?".
+func (e Event) Query() string { return e.Attrs.Get(AttrQuery) }
+
+// Referer is the HTTP referer of a web event.
+func (e Event) Referer() string { return e.Attrs.Get(AttrReferer) }
+
+// Bytes is the response body size, or -1 when unknown.
+func (e Event) Bytes() int64 {
+	n, err := strconv.ParseInt(e.Attrs.Get(AttrBytes), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// ResponseTime is nginx $request_time, or 0 when not logged.
+func (e Event) ResponseTime() time.Duration {
+	f, err := strconv.ParseFloat(e.Attrs.Get(AttrResponseTime), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(f * float64(time.Second))
+}
+
+// AccessLogParser parses lines written with one nginx log_format.
+type AccessLogParser struct {
+	format string
+	re     *regexp.Regexp
+	vars   []string // variable name per capture group
+}
+
+var logFormatVar = regexp.MustCompile(`\$\{?([a-zA-Z0-9_]+)\}?`)
+
+// NewAccessLogParser compiles an nginx log_format string such as
+// FormatCombined. Every $variable becomes a field; unknown variables
+// are kept as attributes under their own name.
+func NewAccessLogParser(logFormat string) (*AccessLogParser, error) {
+	locs := logFormatVar.FindAllStringSubmatchIndex(logFormat, -1)
+	if len(locs) == 0 {
+		return nil, errors.New("log_format has no variables")
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	p := &AccessLogParser{format: logFormat}
+	prev := 0
+	for i, loc := range locs {
+		b.WriteString(regexp.QuoteMeta(logFormat[prev:loc[0]]))
+		name := logFormat[loc[2]:loc[3]]
+		p.vars = append(p.vars, name)
+		if i == len(locs)-1 && loc[1] == len(logFormat) {
+			b.WriteString("(.*)")
+		} else {
+			b.WriteString("(.*?)")
+		}
+		prev = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(logFormat[prev:]))
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("log_format: %w", err)
+	}
+	p.re = re
+	return p, nil
+}
+
+// MustAccessLogParser is NewAccessLogParser for the built-in formats.
+func MustAccessLogParser(logFormat string) *AccessLogParser {
+	p, err := NewAccessLogParser(logFormat)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+var (
+	combinedParser = MustAccessLogParser(FormatCombined)
+	commonParser   = MustAccessLogParser(FormatCommon)
+)
+
+// ParseAccessLine parses an Apache/Nginx combined log line, falling back
+// to the common format.
+func ParseAccessLine(line string) (Event, error) {
+	ev, err := combinedParser.Parse(line)
+	if err == nil {
+		return ev, nil
+	}
+	return commonParser.Parse(line)
+}
+
+// Parse maps one access log line to a web Event.
+func (p *AccessLogParser) Parse(line string) (Event, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return Event{}, errors.New("empty line")
+	}
+	m := p.re.FindStringSubmatch(line)
+	if m == nil {
+		return Event{}, errors.New("line does not match log_format")
+	}
+
+	ev := Event{Service: "web", RawLine: line, Attrs: Attrs{}}
+	for i, name := range p.vars {
+		v := m[i+1]
+		if err := ev.setAccessVar(name, v); err != nil {
+			return Event{}, err
+		}
+	}
+	if ev.TS.IsZero() {
+		return Event{}, errors.New("log_format has no timestamp")
+	}
+	return ev, nil
+}
+
+func (ev *Event) setAccessVar(name, v string) error {
+	if v == "-" {
+		v = ""
+	}
+	switch name {
+	case "remote_addr":
+		ev.IP = v
+	case "remote_user":
+		ev.User = v
+	case "time_local":
+		ts, err := time.Parse("02/Jan/2006:15:04:05 -0700", v)
+		if err != nil {
+			return err
+		}
+		ev.TS = ts
+	case "time_iso8601":
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return err
+		}
+		ev.TS = ts
+	case "msec":
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		ev.TS = time.UnixMilli(int64(f * 1000)).UTC()
+	case "request":
+		// "GET /path?x=1 HTTP/1.1"
+		parts := strings.Fields(v)
+		if len(parts) >= 1 {
+			ev.Attrs[AttrMethod] = parts[0]
+		}
+		if len(parts) >= 2 {
+			ev.setRequestURI(parts[1])
+		}
+		if len(parts) >= 3 {
+			ev.Attrs[AttrProtocol] = parts[2]
+		}
+	case "request_method":
+		ev.Attrs[AttrMethod] = v
+	case "request_uri":
+		ev.setRequestURI(v)
+	case "uri":
+		ev.Path = v
+	case "args", "query_string":
+		ev.Attrs[AttrQuery] = v
+	case "server_protocol":
+		ev.Attrs[AttrProtocol] = v
+	case "status":
+		ev.Status = v
+	case "body_bytes_sent", "bytes_sent":
+		if v == "" {
+			v = "0"
+		}
+		ev.Attrs[AttrBytes] = v
+	case "http_referer":
+		ev.Attrs[AttrReferer] = v
+	case "http_user_agent":
+		ev.Attrs[AttrUserAgent] = v
+	case "request_time":
+		ev.Attrs[AttrResponseTime] = v
+	default:
+		if v != "" {
+			ev.Attrs[name] = v
+		}
+	}
+	return nil
+}
+
+func (ev *Event) setRequestURI(uri string) {
+	path, query, _ := strings.Cut(uri, "?")
+	ev.Path = path
+	if query != "" {
+		ev.Attrs[AttrQuery] = query
+	}
+}
@@ -0,0 +1,105 @@
+package normalizer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccessLogParser(t *testing.T) {
+	ts := time.Date(2026, 2, 1, 12, 0, 1, 0, time.FixedZone("", 9*3600))
+	tests := []struct {
+		name    string
+		format  string // "" = ParseAccessLine
+		line    string
+		ip      string
+		user    string
+		method  string
+		path    string
+		query   string
+		status  string
+		bytes   int64
+		referer string
+		ua      string
+		rt      time.Duration
+	}{
+		{
+			name:   "combined",
+			line:   `203.0.113.7 - - [01/Feb/2026:12:00:01 +0900] "GET /admin/login.php?next=%2F&x=1 HTTP/1.1" 404 153 "https://example.com/" "Mozilla/5.0 (X11; Linux x86_64)"`,
+			ip:     "203.0.113.7",
+			method: "GET", path: "/admin/login.php", query: "next=%2F&x=1", status: "404", bytes: 153,
+			referer: "https://example.com/", ua: "Mozilla/5.0 (X11; Linux x86_64)",
+		},
+		{
+			name:   "common falls back",
+			line:   `203.0.113.7 - alice [01/Feb/2026:12:00:01 +0900] "POST /login HTTP/1.0" 200 -`,
+			ip:     "203.0.113.7",
+			user:   "alice",
+			method: "POST", path: "/login", status: "200", bytes: 0,
+		},
+		{
+			name:   "custom log_format with request_time",
+			format: `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent" $request_time`,
+			line:   `198.51.100.2 - - [01/Feb/2026:12:00:01 +0900] "GET /.env HTTP/1.1" 403 0 "-" "curl/8.0" 0.250`,
+			ip:     "198.51.100.2",
+			method: "GET", path: "/.env", status: "403", bytes: 0,
+			ua: "curl/8.0", rt: 250 * time.Millisecond,
+		},
+		{
+			name:   "split method and uri",
+			format: `$remote_addr [$time_local] $request_method $uri $args $status`,
+			line:   `198.51.100.2 [01/Feb/2026:12:00:01 +0900] HEAD /wp-admin/ a=b 301`,
+			ip:     "198.51.100.2",
+			method: "HEAD", path: "/wp-admin/", query: "a=b", status: "301", bytes: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ev Event
+			var err error
+			if tt.format == "" {
+				ev, err = ParseAccessLine(tt.line)
+			} else {
+				var p *AccessLogParser
+				if p, err = NewAccessLogParser(tt.format); err != nil {
+					t.Fatal(err)
+				}
+				ev, err = p.Parse(tt.line)
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.line, err)
+			}
+			if !ev.TS.Equal(ts) {
+				t.Errorf("TS = %v, want %v", ev.TS, ts)
+			}
+			if ev.Service != "web" || ev.IP != tt.ip || ev.User != tt.user || ev.Path != tt.path || ev.Status != tt.status {
+				t.Errorf("service=%s ip=%s user=%s path=%s status=%s, want web/%s/%s/%s/%s",
+					ev.Service, ev.IP, ev.User, ev.Path, ev.Status, tt.ip, tt.user, tt.path, tt.status)
+			}
+			if ev.Method() != tt.method || ev.Query() != tt.query || ev.Bytes() != tt.bytes {
+				t.Errorf("method=%s query=%s bytes=%d, want %s/%s/%d", ev.Method(), ev.Query(), ev.Bytes(), tt.method, tt.query, tt.bytes)
+			}
+			if ev.Referer() != tt.referer || ev.UserAgent() != tt.ua || ev.ResponseTime() != tt.rt {
+				t.Errorf("referer=%q ua=%q rt=%v, want %q/%q/%v", ev.Referer(), ev.UserAgent(), ev.ResponseTime(), tt.referer, tt.ua, tt.rt)
+			}
+		})
+	}
+}
+
+func TestAccessLogParserErrors(t *testing.T) {
+	if _, err := NewAccessLogParser("no variables here"); err == nil {
+		t.Error("NewAccessLogParser without variables: want error")
+	}
+	p := MustAccessLogParser(`$remote_addr $status`)
+	if _, err := p.Parse("1.2.3.4 200"); err == nil {
+		t.Error("log_format without a timestamp: want error")
+	}
+	for _, line := range []string{
+		"",
+		"2026-02-01T12:00:01Z service=web path=/",
+		`203.0.113.7 - - [yesterday] "GET / HTTP/1.1" 200 1`,
+	} {
+		if ev, err := ParseAccessLine(line); err == nil {
+			t.Errorf("ParseAccessLine(%q) = %+v, want error", line, ev)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package normalizer
+
+// Attrs holds every key a parser read from the line, including the ones
+// also copied into Event's typed fields, so detectors and rules can
+// match on source-specific data (method, ua, reason, ...).
+type Attrs map[string]string
+
+// Get returns the value for key, or "" (nil-safe).
+func (a Attrs) Get(key string) string {
+	return a[key]
+}
+
+// Well-known attribute keys.
+const (
+	AttrMethod    = "method"
+	AttrUserAgent = "ua"
+	AttrReason    = "reason"
+)
+
+// Attr returns the raw attribute for key.
+func (e Event) Attr(key string) string { return e.Attrs.Get(key) }
+
+// Method is the HTTP method of a web event.
+func (e Event) Method() string { return e.Attrs.Get(AttrMethod) }
+
+// UserAgent is the HTTP user agent of a web event.
+func (e Event) UserAgent() string { return e.Attrs.Get(AttrUserAgent) }
+
+// Reason is the failure reason of an auth/ssh event
+// (bad_password, publickey, ...).
+func (e Event) Reason() string { return e.Attrs.Get(AttrReason) }
+
+// Field returns a typed field by its logfmt name ("service", "ip", ...)
+// and falls back to Attrs for anything else.
+func (e Event) Field(name string) string {
+	switch name {
+	case "service":
+		return e.Service
+	case "action":
+		return e.Action
+	case "user":
+		return e.User
+	case "ip":
+		return e.IP
+	case "status":
+		return e.Status
+	case "path":
+		return e.Path
+	default:
+		return e.Attrs.Get(name)
+	}
+}
@@ -0,0 +1,333 @@
+package normalizer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONPaths lists candidate paths for one field; the first path present
+// in the object wins. Paths are dotted ("event.src.ip"), array elements
+// are addressed by index ("tags.0"). In YAML a single string is accepted.
+type JSONPaths []string
+
+func (p *JSONPaths) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*p = JSONPaths{n.Value}
+		return nil
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*p = list
+	return nil
+}
+
+// JSONMapping says which JSON path feeds each Event field, e.g. for
+//
+//	{"time":"2026-02-01T12:00:01Z","event":"login","result":"failure","src_ip":"1.2.3.4","user":{"name":"alice"}}
+//
+// the mapping
+//
+//	ts: time
+//	action: event
+//	status: result
+//	ip: src_ip
+//	user: user.name
+//	defaults: {service: auth}
+//	values:
+//	  status: {failure: FAIL, success: SUCCESS}
+type JSONMapping struct {
+	TS      JSONPaths `yaml:"ts"`
+	Service JSONPaths `yaml:"service"`
+	Action  JSONPaths `yaml:"action"`
+	User    JSONPaths `yaml:"user"`
+	IP      JSONPaths `yaml:"ip"`
+	Status  JSONPaths `yaml:"status"`
+	Path    JSONPaths `yaml:"path"`
+
+	// TSFormats are tried in order: "rfc3339", "unix", "unix_ms",
+	// "unix_us", "unix_ns" or a Go time layout. Empty means RFC 3339,
+	// a few common layouts, and unix epochs guessed by magnitude.
+	TSFormats []string `yaml:"ts_formats"`
+
+	// Values rewrites raw values per field name, e.g.
+	// {"status": {"failure": "FAIL"}}. Lookups fall back to lower case.
+	Values map[string]map[string]string `yaml:"values"`
+
+	// Defaults fills fields the line does not carry, e.g. {"service": "app"}.
+	Defaults map[string]string `yaml:"defaults"`
+}
+
+// DefaultJSONMapping covers common field names of structured app logs.
+var DefaultJSONMapping = JSONMapping{
+	TS:      JSONPaths{"ts", "time", "timestamp", "@timestamp"},
+	Service: JSONPaths{"service.name", "service", "app"},
+	Action:  JSONPaths{"event.action", "action", "event"},
+	User:    JSONPaths{"user.name", "user", "username"},
+	IP:      JSONPaths{"source.ip", "ip", "src_ip", "client_ip", "remote_addr"},
+	Status:  JSONPaths{"event.outcome", "status", "result", "outcome"},
+	Path:    JSONPaths{"url.path", "path", "uri"},
+	Values: map[string]map[string]string{
+		"status": {
+			"fail": "FAIL", "failure": "FAIL", "failed": "FAIL",
+			"success": "SUCCESS", "ok": "SUCCESS", "succeeded": "SUCCESS",
+		},
+	},
+}
+
+// LoadJSONMapping reads a JSONMapping from a YAML or JSON file.
+func LoadJSONMapping(path string) (JSONMapping, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return JSONMapping{}, err
+	}
+	var m JSONMapping
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return JSONMapping{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// JSONParser parses JSON-lines logs with a JSONMapping. Every leaf of the
+// object is also kept in Event.Attrs under its dotted path.
+type JSONParser struct {
+	m JSONMapping
+}
+
+func NewJSONParser(m JSONMapping) *JSONParser {
+	return &JSONParser{m: m}
+}
+
+var defaultJSON = NewJSONParser(DefaultJSONMapping)
+
+// ParseJSONLine parses a JSON log line with DefaultJSONMapping.
+func ParseJSONLine(line string) (Event, error) {
+	return defaultJSON.Parse(line)
+}
+
+func (p *JSONParser) Parse(line string) (Event, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return Event{}, errors.New("empty line")
+	}
+
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return Event{}, err
+	}
+
+	ev := Event{RawLine: line, Attrs: Attrs{}}
+	flattenJSON("", obj, ev.Attrs)
+
+	get := func(field string, paths JSONPaths) string {
+		v := ""
+		for _, path := range paths {
+			raw, ok := lookupJSON(obj, path)
+			if !ok || isJSONContainer(raw) {
+				continue
+			}
+			v = jsonString(raw)
+			break
+		}
+		if v == "" {
+			v = p.m.Defaults[field]
+		}
+		if vm, ok := p.m.Values[field]; ok {
+			if mapped, ok := vm[v]; ok {
+				v = mapped
+			} else if mapped, ok := vm[strings.ToLower(v)]; ok {
+				v = mapped
+			}
+		}
+		return v
+	}
+
+	ev.Service = get("service", p.m.Service)
+	ev.Action = get("action", p.m.Action)
+	ev.User = get("user", p.m.User)
+	ev.IP = get("ip", p.m.IP)
+	ev.Status = get("status", p.m.Status)
+	ev.Path = get("path", p.m.Path)
+
+	var rawTS any
+	for _, path := range p.m.TS {
+		if v, ok := lookupJSON(obj, path); ok && !isJSONContainer(v) {
+			rawTS = v
+			break
+		}
+	}
+	if rawTS == nil {
+		return Event{}, errors.New("missing timestamp")
+	}
+	ts, err := parseJSONTime(rawTS, p.m.TSFormats)
+	if err != nil {
+		return Event{}, err
+	}
+	ev.TS = ts
+
+	if ev.Service == "" {
+		return Event{}, errors.New("missing service field")
+	}
+	return ev, nil
+}
+
+// lookupJSON walks a dotted path. A key that itself contains dots
+// ("user.name" as a flat key) is matched before descending.
+func lookupJSON(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	switch x := v.(type) {
+	case map[string]any:
+		if val, ok := x[path]; ok {
+			return val, true
+		}
+		for i := 0; i < len(path); i++ {
+			if path[i] != '.' {
+				continue
+			}
+			if child, ok := x[path[:i]]; ok {
+				if val, ok := lookupJSON(child, path[i+1:]); ok {
+					return val, true
+				}
+			}
+		}
+	case []any:
+		head, rest, _ := strings.Cut(path, ".")
+		idx, err := strconv.Atoi(head)
+		if err != nil || idx < 0 || idx >= len(x) {
+			return nil, false
+		}
+		return lookupJSON(x[idx], rest)
+	}
+	return nil, false
+}
+
+func flattenJSON(prefix string, v any, out Attrs) {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, child := range x {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flattenJSON(k, child, out)
+		}
+	case []any:
+		for i, child := range x {
+			flattenJSON(prefix+"."+strconv.Itoa(i), child, out)
+		}
+	default:
+		out[prefix] = jsonString(v)
+	}
+}
+
+func isJSONContainer(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+func jsonString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+}
+
+var defaultTSLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC1123Z,
+	"02/Jan/2006:15:04:05 -0700",
+}
+
+func parseJSONTime(v any, formats []string) (time.Time, error) {
+	s := jsonString(v)
+	if len(formats) == 0 {
+		if n, ok := v.(json.Number); ok {
+			return guessEpoch(n)
+		}
+		for _, layout := range defaultTSLayouts {
+			if ts, err := time.Parse(layout, s); err == nil {
+				return ts, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unrecognized timestamp %q", s)
+	}
+
+	for _, f := range formats {
+		var (
+			ts  time.Time
+			err error
+		)
+		switch f {
+		case "rfc3339":
+			ts, err = time.Parse(time.RFC3339Nano, s)
+		case "unix", "unix_ms", "unix_us", "unix_ns":
+			ts, err = parseEpoch(s, f)
+		default:
+			ts, err = time.Parse(f, s)
+		}
+		if err == nil {
+			return ts, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("timestamp %q matches none of %q", s, formats)
+}
+
+func parseEpoch(s, unit string) (time.Time, error) {
+	nsPer := map[string]int64{"unix": 1e9, "unix_ms": 1e6, "unix_us": 1e3, "unix_ns": 1}[unit]
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(0, n*nsPer).UTC(), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(f*float64(nsPer))).UTC(), nil
+}
+
+// guessEpoch picks seconds/ms/us/ns by magnitude (dates after 2001).
+func guessEpoch(n json.Number) (time.Time, error) {
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch a := math.Abs(f); {
+	case a < 1e11:
+		return parseEpoch(n.String(), "unix")
+	case a < 1e14:
+		return parseEpoch(n.String(), "unix_ms")
+	case a < 1e17:
+		return parseEpoch(n.String(), "unix_us")
+	default:
+		return parseEpoch(n.String(), "unix_ns")
+	}
+}
@@ -0,0 +1,167 @@
+package normalizer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// appMapping is the mapping from the JSONMapping doc comment.
+var appMapping = JSONMapping{
+	TS:       JSONPaths{"time"},
+	Action:   JSONPaths{"event"},
+	Status:   JSONPaths{"result"},
+	IP:       JSONPaths{"src_ip"},
+	User:     JSONPaths{"user.name"},
+	Defaults: map[string]string{"service": "auth"},
+	Values:   map[string]map[string]string{"status": {"failure": "FAIL", "success": "SUCCESS"}},
+}
+
+func TestJSONParser(t *testing.T) {
+	ts := time.Date(2026, 2, 1, 12, 0, 1, 0, time.UTC)
+	tests := []struct {
+		name    string
+		m       *JSONMapping // nil = DefaultJSONMapping
+		line    string
+		ts      time.Time
+		service string
+		action  string
+		status  string
+		user    string
+		ip      string
+		path    string
+		attrs   Attrs
+	}{
+		{
+			name:    "request example",
+			m:       &appMapping,
+			line:    `{"time":"2026-02-01T12:00:01Z","event":"login","result":"failure","src_ip":"1.2.3.4","user":{"name":"alice"}}`,
+			ts:      ts,
+			service: "auth", action: "login", status: "FAIL", user: "alice", ip: "1.2.3.4",
+			attrs: Attrs{"user.name": "alice", "result": "failure"},
+		},
+		{
+			name:    "value mapping falls back to lower case",
+			m:       &appMapping,
+			line:    `{"time":"2026-02-01T12:00:01Z","event":"login","result":"Success","src_ip":"1.2.3.4"}`,
+			ts:      ts,
+			service: "auth", action: "login", status: "SUCCESS", ip: "1.2.3.4",
+		},
+		{
+			name:    "flat dotted key",
+			m:       &appMapping,
+			line:    `{"time":"2026-02-01T12:00:01Z","event":"login","user.name":"bob"}`,
+			ts:      ts,
+			service: "auth", action: "login", user: "bob",
+		},
+		{
+			name:    "default mapping, ECS style",
+			line:    `{"@timestamp":"2026-02-01T12:00:01.250Z","service":{"name":"api"},"event":{"action":"login","outcome":"failure"},"source":{"ip":"10.0.0.9"},"url":{"path":"/login"},"tags":["a","b"]}`,
+			ts:      ts.Add(250 * time.Millisecond),
+			service: "api", action: "login", status: "FAIL", ip: "10.0.0.9", path: "/login",
+			attrs: Attrs{"tags.1": "b", "event.outcome": "failure"},
+		},
+		{
+			name:    "epoch seconds",
+			line:    `{"ts":1769947201,"service":"auth","status":"ok"}`,
+			ts:      ts,
+			service: "auth", status: "SUCCESS",
+		},
+		{
+			name:    "epoch milliseconds",
+			line:    `{"ts":1769947201500,"service":"auth"}`,
+			ts:      ts.Add(500 * time.Millisecond),
+			service: "auth",
+		},
+		{
+			name:    "space separated layout",
+			line:    `{"timestamp":"2026-02-01 12:00:01","app":"worker","ok":true}`,
+			ts:      ts,
+			service: "worker",
+			attrs:   Attrs{"ok": "true"},
+		},
+		{
+			name: "explicit formats",
+			m: &JSONMapping{
+				TS:        JSONPaths{"when"},
+				Service:   JSONPaths{"svc"},
+				TSFormats: []string{"unix_ms", "02/Jan/2006:15:04:05 -0700"},
+			},
+			line:    `{"when":"01/Feb/2026:12:00:01 +0000","svc":"web"}`,
+			ts:      ts,
+			service: "web",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewJSONParser(DefaultJSONMapping)
+			if tt.m != nil {
+				p = NewJSONParser(*tt.m)
+			}
+			ev, err := p.Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.line, err)
+			}
+			if !ev.TS.Equal(tt.ts) {
+				t.Errorf("TS = %v, want %v", ev.TS, tt.ts)
+			}
+			if ev.Service != tt.service || ev.Action != tt.action || ev.Status != tt.status ||
+				ev.User != tt.user || ev.IP != tt.ip || ev.Path != tt.path {
+				t.Errorf("service=%s action=%s status=%s user=%s ip=%s path=%s, want %s/%s/%s/%s/%s/%s",
+					ev.Service, ev.Action, ev.Status, ev.User, ev.IP, ev.Path,
+					tt.service, tt.action, tt.status, tt.user, tt.ip, tt.path)
+			}
+			checkAttrs(t, ev, tt.attrs)
+		})
+	}
+}
+
+func TestJSONParserErrors(t *testing.T) {
+	strict := NewJSONParser(JSONMapping{TS: JSONPaths{"time"}, Service: JSONPaths{"svc"}, TSFormats: []string{"unix"}})
+	tests := []struct {
+		name string
+		p    *JSONParser
+		line string
+	}{
+		{"empty", defaultJSON, ""},
+		{"not json", defaultJSON, "2026-02-01T12:00:01Z service=auth"},
+		{"missing timestamp", defaultJSON, `{"service":"auth"}`},
+		{"timestamp is an object", defaultJSON, `{"ts":{"s":1},"service":"auth"}`},
+		{"unrecognized timestamp", defaultJSON, `{"ts":"yesterday","service":"auth"}`},
+		{"missing service", defaultJSON, `{"ts":"2026-02-01T12:00:01Z","user":"alice"}`},
+		{"no format matches", strict, `{"time":"2026-02-01T12:00:01Z","svc":"x"}`},
+	}
+	for _, tt := range tests {
+		if ev, err := tt.p.Parse(tt.line); err == nil {
+			t.Errorf("%s: Parse(%q) = %+v, want error", tt.name, tt.line, ev)
+		}
+	}
+}
+
+func TestLoadJSONMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	data := "ts: time\naction: event\nstatus: result\nip: [src_ip, client.ip]\nuser: user.name\n" +
+		"defaults: {service: auth}\nvalues:\n  status: {failure: FAIL, success: SUCCESS}\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadJSONMapping(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err := NewJSONParser(m).Parse(`{"time":"2026-02-01T12:00:01Z","event":"login","result":"failure","client":{"ip":"5.6.7.8"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Service != "auth" || ev.Action != "login" || ev.Status != "FAIL" || ev.IP != "5.6.7.8" {
+		t.Errorf("got %+v", ev)
+	}
+
+	if err := os.WriteFile(path, []byte("ts: time\nbogus: x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadJSONMapping(path); err == nil {
+		t.Error("unknown key: want error")
+	}
+}
@@ -0,0 +1,123 @@
+package normalizer
+
+import "strings"
+
+// kv is one logfmt key/value pair.
+type kv struct {
+	key   string
+	value string
+}
+
+// scanLogfmt tokenizes a logfmt string:
+//
+//	key=value key="quoted value" key="with \"escapes\"" key=
+//
+// Tokens that are not key=value (bare words, "=value", unterminated
+// quotes) are returned in bad instead of being dropped.
+func scanLogfmt(s string) (pairs []kv, bad []string) {
+	i := 0
+	n := len(s)
+	for {
+		for i < n && isSpace(s[i]) {
+			i++
+		}
+		if i >= n {
+			return pairs, bad
+		}
+
+		start := i
+		for i < n && s[i] != '=' && !isSpace(s[i]) && s[i] != '"' {
+			i++
+		}
+		key := s[start:i]
+
+		if i >= n || s[i] != '=' || key == "" {
+			// not a key=... token: skip to the next space (respecting quotes)
+			i = skipToken(s, i)
+			bad = append(bad, s[start:i])
+			continue
+		}
+		i++ // '='
+
+		if i >= n || isSpace(s[i]) {
+			pairs = append(pairs, kv{key: key})
+			continue
+		}
+
+		if s[i] != '"' {
+			vs := i
+			for i < n && !isSpace(s[i]) {
+				i++
+			}
+			pairs = append(pairs, kv{key: key, value: s[vs:i]})
+			continue
+		}
+
+		v, end, ok := unquote(s, i)
+		if !ok {
+			bad = append(bad, s[start:])
+			return pairs, bad
+		}
+		i = end
+		if i < n && !isSpace(s[i]) {
+			// garbage glued to the closing quote: key="a"b
+			i = skipToken(s, i)
+			bad = append(bad, s[start:i])
+			continue
+		}
+		pairs = append(pairs, kv{key: key, value: v})
+	}
+}
+
+// unquote reads a double-quoted string starting at s[i] == '"'.
+// It returns the unescaped value and the index just past the closing quote.
+func unquote(s string, i int) (string, int, bool) {
+	var b strings.Builder
+	i++ // opening quote
+	for i < len(s) {
+		c := s[i]
+		switch c {
+		case '"':
+			return b.String(), i + 1, true
+		case '\\':
+			if i+1 >= len(s) {
+				return "", i, false
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default: // \" \\ and anything else: literal
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+		i++
+	}
+	return "", i, false
+}
+
+// skipToken advances past a whitespace-delimited token, treating a
+// quoted section as part of the token.
+func skipToken(s string, i int) int {
+	for i < len(s) && !isSpace(s[i]) {
+		if s[i] == '"' {
+			if _, end, ok := unquote(s, i); ok {
+				i = end
+				continue
+			}
+			return len(s)
+		}
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
@@ -15,6 +15,12 @@ type Event struct {
 	Status  string
 	Path    string
 	RawLine string
+
+	// Attrs keeps every parsed key/value; see attrs.go for accessors.
+	Attrs Attrs
+
+	// Unparsed holds tokens ParseLine could not read as key=value.
+	Unparsed []string
 }
 
 func ParseLine(line string) (Event, error) {
@@ -23,30 +29,32 @@ func ParseLine(line string) (Event, error) {
 		return Event{}, errors.New("empty line")
 	}
 
-	parts := strings.Fields(line)
-	if len(parts) < 2 {
-		return Event{}, errors.New("invalid line format")
-	}
-
 	// 1) timestamp (첫 토큰)
-	ts, err := time.Parse(time.RFC3339, parts[0])
+	head, rest := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		head, rest = line[:i], line[i+1:]
+	}
+	ts, err := time.Parse(time.RFC3339, head)
 	if err != nil {
 		return Event{}, err
 	}
+	if strings.TrimSpace(rest) == "" {
+		return Event{}, errors.New("invalid line format")
+	}
 
 	ev := Event{
 		TS:      ts,
 		RawLine: line,
 	}
 
-	// 2) key=value 토큰 파싱
-	for _, tok := range parts[1:] {
-		kv := strings.SplitN(tok, "=", 2)
-		if len(kv) != 2 {
-			continue
-		}
-		k, v := kv[0], kv[1]
-		v = strings.Trim(v, `"`)
+	// 2) logfmt key=value 파싱 (따옴표 값, 이스케이프, 빈 값 지원)
+	pairs, bad := scanLogfmt(rest)
+	ev.Unparsed = bad
+	ev.Attrs = make(Attrs, len(pairs))
+
+	for _, p := range pairs {
+		k, v := p.key, p.value
+		ev.Attrs[k] = v
 
 		switch k {
 		case "service":
@@ -61,7 +69,6 @@ func ParseLine(line string) (Event, error) {
 			ev.Status = v
 		case "path":
 			ev.Path = v
-			// web.log에는 method, ua도 있지만 Step 1에서는 무시해도 OK
 		}
 	}
 
@@ -0,0 +1,73 @@
+package normalizer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	ts := time.Date(2026, 2, 1, 12, 0, 1, 0, time.UTC)
+	tests := []struct {
+		name     string
+		line     string
+		want     Event // RawLine은 line으로 채워서 비교
+		unparsed []string
+		wantErr  bool
+	}{
+		{
+			name: "plain",
+			line: "2026-02-01T12:00:01Z service=auth action=login user=alice ip=203.0.113.10 status=FAIL reason=bad_password",
+			want: Event{TS: ts, Service: "auth", Action: "login", User: "alice", IP: "203.0.113.10", Status: "FAIL",
+				Attrs: Attrs{"service": "auth", "action": "login", "user": "alice", "ip": "203.0.113.10", "status": "FAIL", "reason": "bad_password"}},
+		},
+		{
+			name: "quoted value with spaces",
+			line: `2026-02-01T12:00:01Z service=web method=GET path=/admin ua="Mozilla/5.0 (X11; Linux)"`,
+			want: Event{TS: ts, Service: "web", Path: "/admin",
+				Attrs: Attrs{"service": "web", "method": "GET", "path": "/admin", "ua": "Mozilla/5.0 (X11; Linux)"}},
+		},
+		{
+			name: "escaped quotes",
+			line: `2026-02-01T12:00:01Z service=app msg="say \"hi\" now"`,
+			want: Event{TS: ts, Service: "app", Attrs: Attrs{"service": "app", "msg": `say "hi" now`}},
+		},
+		{
+			name: "empty value",
+			line: "2026-02-01T12:00:01Z service=auth user= status=FAIL",
+			want: Event{TS: ts, Service: "auth", Status: "FAIL", Attrs: Attrs{"service": "auth", "user": "", "status": "FAIL"}},
+		},
+		{
+			name:     "unparsed tokens are reported",
+			line:     "2026-02-01T12:00:01Z service=auth oops =x user=bob",
+			want:     Event{TS: ts, Service: "auth", User: "bob", Attrs: Attrs{"service": "auth", "user": "bob"}},
+			unparsed: []string{"oops", "=x"},
+		},
+		{name: "empty line", line: "   ", wantErr: true},
+		{name: "bad timestamp", line: "yesterday service=auth", wantErr: true},
+		{name: "no fields", line: "2026-02-01T12:00:01Z", wantErr: true},
+		{name: "missing service", line: "2026-02-01T12:00:01Z user=alice", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLine(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseLine(%q) = %+v, want error", tt.line, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLine(%q): %v", tt.line, err)
+			}
+			if !reflect.DeepEqual(got.Unparsed, tt.unparsed) {
+				t.Errorf("Unparsed = %q, want %q", got.Unparsed, tt.unparsed)
+			}
+			got.Unparsed = nil
+			tt.want.RawLine = tt.line
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLine(%q)\n got %+v\nwant %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package normalizer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Parser turns one raw line into an Event.
+type Parser interface {
+	Parse(line string) (Event, error)
+}
+
+// ParserFunc adapts a plain function to Parser.
+type ParserFunc func(line string) (Event, error)
+
+func (f ParserFunc) Parse(line string) (Event, error) { return f(line) }
+
+// Built-in parser names.
+const (
+	ParserLogfmt = "logfmt" // ParseLine: RFC3339 ts + key=value
+	ParserJSON   = "json"   // ParseJSONLine with DefaultJSONMapping
+	ParserSSHD   = "sshd"   // ParseSSHDLine
+	ParserSyslog = "syslog" // RFC 3164/5424 + body parsers
+	ParserAccess = "access" // nginx/apache combined, falling back to common
+	ParserAuto   = "auto"   // sniff the first lines of the source
+	StdinSource  = "-"      // source name used for standard input
+)
+
+// sniffOrder is the auto-detect candidate list, most specific first, so
+// ties go to the narrower parser (sshd before generic syslog).
+var sniffOrder = []string{ParserLogfmt, ParserJSON, ParserSSHD, ParserAccess, ParserSyslog}
+
+// DefaultSniffLines is how many lines auto-detection looks at.
+const DefaultSniffLines = 20
+
+type binding struct {
+	pattern string
+	parser  string
+}
+
+// Registry maps sources (file paths, stdin) to named parsers and keeps
+// per-source parse counters.
+type Registry struct {
+	mu       sync.Mutex
+	parsers  map[string]Parser
+	bindings []binding
+	sources  map[string]*Source
+	syslog   *SyslogParser
+
+	// SniffLines is how many lines an "auto" source samples before it
+	// locks in a parser.
+	SniffLines int
+}
+
+// NewRegistry returns a registry with the built-in parsers.
+func NewRegistry() *Registry {
+	r := &Registry{
+		parsers:    make(map[string]Parser),
+		sources:    make(map[string]*Source),
+		syslog:     NewSyslogParser(),
+		SniffLines: DefaultSniffLines,
+	}
+	r.parsers[ParserLogfmt] = ParserFunc(ParseLine)
+	r.parsers[ParserJSON] = ParserFunc(ParseJSONLine)
+	r.parsers[ParserSSHD] = ParserFunc(ParseSSHDLine)
+	r.parsers[ParserSyslog] = r.syslog
+	r.parsers[ParserAccess] = ParserFunc(ParseAccessLine)
+	return r
+}
+
+// Register adds or replaces a named parser, e.g. an AccessLogParser for
+// a custom nginx log_format or a JSONParser with its own mapping.
+func (r *Registry) Register(name string, p Parser) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.parsers[name] = p
+}
+
+// Bind routes sources whose name (or base name) matches the glob
+// pattern to the named parser. Bindings are checked in order; use
+// StdinSource ("-") for standard input.
+func (r *Registry) Bind(pattern, parser string) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("bind %q: %w", pattern, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if parser != ParserAuto {
+		if _, ok := r.parsers[parser]; !ok {
+			return fmt.Errorf("bind %q: unknown parser %q", pattern, parser)
+		}
+	}
+	r.bindings = append(r.bindings, binding{pattern: pattern, parser: parser})
+	return nil
+}
+
+// BindProgram makes the syslog parser hand messages from program to the
+// named parser (e.g. an app that logs JSON through syslog). Header
+// attributes are kept; the header time is used if the body has none.
+func (r *Registry) BindProgram(program, parser string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.parsers[parser]
+	if !ok {
+		return fmt.Errorf("bind program %q: unknown parser %q", program, parser)
+	}
+	r.syslog.Register(program, func(ev *Event, msg string) error {
+		body, err := p.Parse(msg)
+		if err != nil {
+			return err
+		}
+		if body.Attrs == nil {
+			body.Attrs = Attrs{}
+		}
+		for k, v := range ev.Attrs {
+			if _, ok := body.Attrs[k]; !ok {
+				body.Attrs[k] = v
+			}
+		}
+		if body.TS.IsZero() {
+			body.TS = ev.TS
+		}
+		body.RawLine = ev.RawLine
+		*ev = body
+		return nil
+	})
+	return nil
+}
+
+func (r *Registry) lookupBinding(name string) string {
+	for _, b := range r.bindings {
+		if ok, _ := filepath.Match(b.pattern, name); ok {
+			return b.parser
+		}
+		if ok, _ := filepath.Match(b.pattern, filepath.Base(name)); ok {
+			return b.parser
+		}
+	}
+	return ParserAuto
+}
+
+// Source returns the per-source parser for name, creating it on first
+// use from the bindings.
+func (r *Registry) Source(name string) *Source {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sources[name]; ok {
+		return s
+	}
+	s := &Source{name: name, reg: r}
+	if pn := r.lookupBinding(name); pn != ParserAuto {
+		s.parserName = pn
+		s.parser = r.parsers[pn]
+	} else {
+		s.sniffLeft = r.SniffLines
+		s.scores = make(map[string]int)
+	}
+	r.sources[name] = s
+	return s
+}
+
+// Stats returns the counters of every source, sorted by name.
+func (r *Registry) Stats() []SourceStats {
+	r.mu.Lock()
+	list := make([]*Source, 0, len(r.sources))
+	for _, s := range r.sources {
+		list = append(list, s)
+	}
+	r.mu.Unlock()
+
+	out := make([]SourceStats, 0, len(list))
+	for _, s := range list {
+		out = append(out, s.Stats())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
+
+// candidates returns the auto-detect parsers in sniffOrder.
+func (r *Registry) candidates() []Parser {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Parser, len(sniffOrder))
+	for i, name := range sniffOrder {
+		out[i] = r.parsers[name]
+	}
+	return out
+}
+
+// Sniff picks the built-in parser that accepts the most of lines
+// (ties go to the more specific parser). It returns "" when none fits.
+func (r *Registry) Sniff(lines []string) string {
+	cands := r.candidates()
+	best, bestN := "", 0
+	for i, name := range sniffOrder {
+		p := cands[i]
+		n := 0
+		for _, l := range lines {
+			if _, err := p.Parse(l); err == nil {
+				n++
+			}
+		}
+		if n > bestN {
+			best, bestN = name, n
+		}
+	}
+	return best
+}
+
+// SourceStats is a snapshot of one source's counters.
+type SourceStats struct {
+	Source string
+	Parser string // "" while still sniffing
+	Lines  uint64
+	Errors uint64
+}
+
+// Source parses the lines of one input. A Source is meant to be fed from
+// one goroutine; its counters may be read from any goroutine.
+type Source struct {
+	name string
+	reg  *Registry
+
+	mu         sync.Mutex
+	parser     Parser
+	parserName string
+
+	// auto-detection state
+	sniffLeft int
+	scores    map[string]int
+
+	lines  atomic.Uint64
+	errors atomic.Uint64
+}
+
+func (s *Source) Name() string { return s.name }
+
+// ParserName is the bound or detected parser ("" while sniffing).
+func (s *Source) ParserName() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.parserName
+}
+
+func (s *Source) Stats() SourceStats {
+	return SourceStats{
+		Source: s.name,
+		Parser: s.ParserName(),
+		Lines:  s.lines.Load(),
+		Errors: s.errors.Load(),
+	}
+}
+
+// Parse parses one line. While an auto source is sniffing, every
+// candidate is tried and the line is parsed by the first that accepts
+// it; after SniffLines lines the best-scoring parser is locked in.
+func (s *Source) Parse(line string) (Event, error) {
+	s.lines.Add(1)
+
+	s.mu.Lock()
+	p := s.parser
+	s.mu.Unlock()
+
+	var (
+		ev  Event
+		err error
+	)
+	if p != nil {
+		ev, err = p.Parse(line)
+	} else {
+		ev, err = s.sniff(line)
+	}
+	if err != nil {
+		s.errors.Add(1)
+	}
+	return ev, err
+}
+
+func (s *Source) sniff(line string) (Event, error) {
+	cands := s.reg.candidates()
+
+	var (
+		first    Event
+		firstErr error
+		found    bool
+		p        Parser
+	)
+	for i, name := range sniffOrder {
+		ev, err := cands[i].Parse(line)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		s.scores[name]++
+		if !found {
+			first, found = ev, true
+		}
+	}
+
+	s.sniffLeft--
+	if s.sniffLeft <= 0 {
+		best, bestN := "", 0
+		for i, name := range sniffOrder {
+			if s.scores[name] > bestN {
+				best, bestN = name, s.scores[name]
+				p = cands[i]
+			}
+		}
+		if best != "" {
+			s.mu.Lock()
+			s.parserName = best
+			s.parser = p
+			s.mu.Unlock()
+		} else {
+			// nothing matched yet: keep sniffing
+			s.sniffLeft = s.reg.SniffLines
+		}
+	}
+
+	if !found {
+		return Event{}, fmt.Errorf("no parser accepts line: %w", firstErr)
+	}
+	return first, nil
+}
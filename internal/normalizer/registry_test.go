@@ -0,0 +1,203 @@
+package normalizer
+
+import (
+	"reflect"
+	"testing"
+)
+
+var sniffSamples = map[string][]string{
+	ParserLogfmt: {
+		"2026-02-01T12:00:01Z service=auth action=login user=alice ip=203.0.113.10 status=FAIL",
+		"2026-02-01T12:00:02Z service=web method=GET path=/admin ip=203.0.113.10 status=404",
+	},
+	ParserJSON: {
+		`{"ts":"2026-02-01T12:00:01Z","service":"auth","user":"alice","status":"fail"}`,
+		`{"ts":"2026-02-01T12:00:02Z","service":"auth","user":"bob","status":"ok"}`,
+	},
+	ParserSSHD: {
+		"Feb  1 12:00:01 web01 sshd[1234]: Failed password for root from 1.2.3.4 port 5555 ssh2",
+		"Feb  1 12:00:02 web01 sshd[1234]: Accepted publickey for deploy from 10.0.0.5 port 40022 ssh2",
+	},
+	ParserAccess: {
+		`203.0.113.7 - - [01/Feb/2026:12:00:01 +0000] "GET /admin HTTP/1.1" 404 153 "-" "curl/8.0"`,
+		`203.0.113.7 - - [01/Feb/2026:12:00:02 +0000] "GET /.env HTTP/1.1" 404 153`,
+	},
+	ParserSyslog: {
+		"Feb  1 12:00:01 web01 sudo[42]: alice : TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/bin/bash",
+		"<13>1 2026-02-01T12:00:02Z web01 myapp - - - started",
+	},
+}
+
+func TestRegistrySniff(t *testing.T) {
+	r := NewRegistry()
+	for want, lines := range sniffSamples {
+		if got := r.Sniff(lines); got != want {
+			t.Errorf("Sniff(%s lines) = %q", want, got)
+		}
+	}
+	if got := r.Sniff([]string{"hello", "world"}); got != "" {
+		t.Errorf("Sniff(garbage) = %q, want \"\"", got)
+	}
+}
+
+func TestSourceSniffLocksIn(t *testing.T) {
+	for want, lines := range sniffSamples {
+		t.Run(want, func(t *testing.T) {
+			r := NewRegistry()
+			r.SniffLines = len(lines)
+			s := r.Source("app.log")
+			for _, l := range lines {
+				if _, err := s.Parse(l); err != nil {
+					t.Fatalf("Parse(%q) while sniffing: %v", l, err)
+				}
+			}
+			if got := s.ParserName(); got != want {
+				t.Errorf("locked in %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSourceSniffFallback(t *testing.T) {
+	r := NewRegistry()
+	r.SniffLines = 2
+	s := r.Source("mixed.log")
+
+	// 아무 파서도 못 읽으면 결정하지 않고 계속 감지
+	for _, l := range []string{"garbage one", "garbage two"} {
+		if _, err := s.Parse(l); err == nil {
+			t.Errorf("Parse(%q): want error", l)
+		}
+	}
+	if got := s.ParserName(); got != "" {
+		t.Fatalf("parser locked in as %q after unparseable lines", got)
+	}
+
+	// 감지 중에는 받아주는 첫 파서로 읽음
+	ev, err := s.Parse(sniffSamples[ParserJSON][0])
+	if err != nil || ev.User != "alice" {
+		t.Fatalf("Parse while sniffing = %+v, %v", ev, err)
+	}
+	if _, err := s.Parse(sniffSamples[ParserJSON][1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.ParserName(); got != ParserJSON {
+		t.Errorf("locked in %q, want %q", got, ParserJSON)
+	}
+
+	// 결정된 뒤에는 다른 형식의 줄은 에러
+	if _, err := s.Parse(sniffSamples[ParserLogfmt][0]); err == nil {
+		t.Error("logfmt line after locking in json: want error")
+	}
+	want := SourceStats{Source: "mixed.log", Parser: ParserJSON, Lines: 5, Errors: 3}
+	if got := s.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestRegistryBind(t *testing.T) {
+	r := NewRegistry()
+	r.Register("nginx-timed", MustAccessLogParser(FormatCombined+" $request_time"))
+	for _, b := range [][2]string{
+		{"*access*.log", "nginx-timed"},
+		{"/var/log/auth.log", ParserSyslog},
+		{StdinSource, ParserLogfmt},
+		{"*.log", ParserAuto},
+	} {
+		if err := r.Bind(b[0], b[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		source string
+		parser string
+	}{
+		{"/srv/www/access.log", "nginx-timed"}, // 기본 이름으로 매칭
+		{"web-access-2.log", "nginx-timed"},
+		{"/var/log/auth.log", ParserSyslog},
+		{StdinSource, ParserLogfmt},
+		{"other.log", ""}, // auto: 감지 전
+	}
+	for _, tt := range tests {
+		if got := r.Source(tt.source).ParserName(); got != tt.parser {
+			t.Errorf("Source(%q) parser = %q, want %q", tt.source, got, tt.parser)
+		}
+	}
+
+	// 바인딩은 감지보다 우선: sshd 줄도 syslog 파서로
+	ev, err := r.Source("/var/log/auth.log").Parse(sniffSamples[ParserSSHD][0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Service != "ssh" || ev.Program() != "sshd" {
+		t.Errorf("bound syslog parse = %+v", ev)
+	}
+	// 바인딩된 파서가 못 읽는 줄은 다른 형식이라도 에러
+	if _, err := r.Source(StdinSource).Parse(sniffSamples[ParserJSON][0]); err == nil {
+		t.Error("json line on a logfmt-bound source: want error")
+	}
+
+	if r.Source("/srv/www/access.log") != r.Source("/srv/www/access.log") {
+		t.Error("Source is not reused per name")
+	}
+
+	if err := r.Bind("*.log", "nope"); err == nil {
+		t.Error("Bind to an unknown parser: want error")
+	}
+	if err := r.Bind("[", ParserLogfmt); err == nil {
+		t.Error("Bind with a bad pattern: want error")
+	}
+}
+
+func TestRegistryStats(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Bind("b.log", ParserLogfmt); err != nil {
+		t.Fatal(err)
+	}
+	b := r.Source("b.log")
+	b.Parse(sniffSamples[ParserLogfmt][0])
+	b.Parse("bad line")
+	a := r.Source("a.log")
+	a.Parse(sniffSamples[ParserAccess][0])
+
+	want := []SourceStats{
+		{Source: "a.log", Lines: 1},
+		{Source: "b.log", Parser: ParserLogfmt, Lines: 2, Errors: 1},
+	}
+	if got := r.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestRegistryBindProgram(t *testing.T) {
+	r := NewRegistry()
+	r.Register("app-json", NewJSONParser(JSONMapping{
+		Action:   JSONPaths{"event"},
+		Status:   JSONPaths{"result"},
+		Defaults: map[string]string{"service": "app"},
+		Values:   map[string]map[string]string{"status": {"failure": "FAIL"}},
+		TS:       JSONPaths{"time"},
+	}))
+	if err := r.BindProgram("myapp", "app-json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.BindProgram("other", "nope"); err == nil {
+		t.Error("BindProgram to an unknown parser: want error")
+	}
+	if err := r.Bind("*", ParserSyslog); err != nil {
+		t.Fatal(err)
+	}
+
+	line := `2026-02-01T12:00:01Z web01 myapp[7]: {"time":"2026-02-01T12:00:00Z","event":"login","result":"failure"}`
+	ev, err := r.Source("x.log").Parse(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Service != "app" || ev.Action != "login" || ev.Status != "FAIL" || ev.Host() != "web01" || ev.PID() != "7" {
+		t.Errorf("got %+v", ev)
+	}
+	if ev.RawLine != line {
+		t.Errorf("RawLine = %q", ev.RawLine)
+	}
+}
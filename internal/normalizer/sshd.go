@@ -0,0 +1,125 @@
+package normalizer
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Attribute keys filled by the sshd parser.
+const (
+	AttrPort        = "port"
+	AttrAuthMethod  = "auth_method"
+	AttrInvalidUser = "invalid_user"
+	AttrMessage     = "msg"
+	AttrRepeated    = "repeated" // "message repeated N times"
+)
+
+// Port is the client source port.
+func (e Event) Port() string { return e.Attrs.Get(AttrPort) }
+
+// AuthMethod is the ssh auth method (password, publickey, ...).
+func (e Event) AuthMethod() string { return e.Attrs.Get(AttrAuthMethod) }
+
+// InvalidUser reports whether sshd said the account does not exist.
+func (e Event) InvalidUser() bool { return e.Attrs.Get(AttrInvalidUser) == "true" }
+
+// sshdPattern maps one sshd message shape to action/status.
+type sshdPattern struct {
+	re     *regexp.Regexp
+	action string
+	status string
+}
+
+// Named groups: method, invalid, user, ip, port.
+var sshdPatterns = []sshdPattern{
+	{regexp.MustCompile(`^Failed (?P<method>\S+) for (?P<invalid>invalid user )?(?P<user>\S*) from (?P<ip>\S+) port (?P<port>\d+)`), "auth", "FAIL"},
+	{regexp.MustCompile(`^Accepted (?P<method>\S+) for (?P<user>\S+) from (?P<ip>\S+) port (?P<port>\d+)`), "auth", "SUCCESS"},
+	{regexp.MustCompile(`^error: maximum authentication attempts exceeded for (?P<invalid>invalid user )?(?P<user>\S*) from (?P<ip>\S+) port (?P<port>\d+)`), "max_auth", "FAIL"},
+	// "Invalid user" precedes the matching "Failed ... for invalid user" line,
+	// so it is not action=auth (would double count).
+	{regexp.MustCompile(`^Invalid user (?P<user>\S*) from (?P<ip>\S+)(?: port (?P<port>\d+))?`), "invalid_user", "FAIL"},
+	{regexp.MustCompile(`^(?:Disconnected from|Connection closed by) (?:authenticating user|(?P<invalid>invalid) user) (?P<user>\S*) ?(?P<ip>\S+) port (?P<port>\d+)`), "disconnect", "PREAUTH"},
+	{regexp.MustCompile(`^Disconnected from user (?P<user>\S+) (?P<ip>\S+) port (?P<port>\d+)`), "disconnect", "CLOSED"},
+	{regexp.MustCompile(`^(?:Disconnected from|Connection closed by|Received disconnect from) (?P<ip>\S+) port (?P<port>\d+)`), "disconnect", ""},
+	{regexp.MustCompile(`^pam_unix\(sshd:auth\): authentication failure;.*\brhost=(?P<ip>\S+)(?:\s+user=(?P<user>\S+))?`), "pam_auth", "FAIL"},
+	{regexp.MustCompile(`^Connection from (?P<ip>\S+) port (?P<port>\d+)`), "connect", ""},
+}
+
+var sshdRepeated = regexp.MustCompile(`^message repeated (\d+) times: \[ ?(.*?) ?\]$`)
+
+// ParseSSHDMessage maps an OpenSSH sshd log message (without the syslog
+// header) to an ssh Event. Messages it does not recognize still yield an
+// event with service=ssh and the text in the "msg" attribute.
+func ParseSSHDMessage(ts time.Time, msg string) Event {
+	ev := Event{TS: ts, Service: "ssh", RawLine: msg, Attrs: Attrs{}}
+	fillSSHD(&ev, msg)
+	return ev
+}
+
+func fillSSHD(ev *Event, msg string) {
+	msg = strings.TrimSpace(msg)
+	if m := sshdRepeated.FindStringSubmatch(msg); m != nil {
+		ev.Attrs[AttrRepeated] = m[1]
+		msg = m[2]
+	}
+	msg = strings.TrimSuffix(msg, " [preauth]")
+
+	for _, p := range sshdPatterns {
+		m := p.re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		ev.Action = p.action
+		ev.Status = p.status
+		for i, name := range p.re.SubexpNames() {
+			if name == "" || m[i] == "" {
+				continue
+			}
+			switch name {
+			case "user":
+				ev.User = m[i]
+			case "ip":
+				ev.IP = m[i]
+			case "port":
+				ev.Attrs[AttrPort] = m[i]
+			case "method":
+				ev.Attrs[AttrAuthMethod] = m[i]
+			case "invalid":
+				ev.Attrs[AttrInvalidUser] = "true"
+			}
+		}
+		if p.action == "invalid_user" {
+			ev.Attrs[AttrInvalidUser] = "true"
+		}
+		return
+	}
+	ev.Attrs[AttrMessage] = msg
+}
+
+// ParseSSHDLine parses a classic sshd syslog line as written to
+// /var/log/auth.log or /var/log/secure:
+//
+//	Feb  1 12:00:01 web01 sshd[1234]: Failed password for invalid user admin from 1.2.3.4 port 5555 ssh2
+//
+// Lines from other programs are rejected; use ParseSyslog for mixed files.
+func ParseSSHDLine(line string) (Event, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return Event{}, errors.New("empty line")
+	}
+	h, msg, err := parseSyslogHeader(line)
+	if err != nil {
+		return Event{}, err
+	}
+	if h.Program != "sshd" {
+		return Event{}, errors.New("not an sshd line: " + h.Program)
+	}
+
+	ev := h.event(line)
+	if err := parseSSHDBody(&ev, msg); err != nil {
+		return Event{}, err
+	}
+	return ev, nil
+}
@@ -0,0 +1,99 @@
+package normalizer
+
+import (
+	"testing"
+	"time"
+)
+
+// checkAttrs reports every key of want that ev.Attrs lacks or differs on.
+func checkAttrs(t *testing.T, ev Event, want Attrs) {
+	t.Helper()
+	for k, v := range want {
+		if got, ok := ev.Attrs[k]; !ok || got != v {
+			t.Errorf("Attrs[%q] = %q (present %v), want %q", k, got, ok, v)
+		}
+	}
+}
+
+func TestParseSSHDLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		action string
+		status string
+		user   string
+		ip     string
+		attrs  Attrs
+	}{
+		{
+			line:   "2026-02-01T12:00:01Z web01 sshd[1234]: Failed password for invalid user admin from 1.2.3.4 port 5555 ssh2",
+			action: "auth", status: "FAIL", user: "admin", ip: "1.2.3.4",
+			attrs: Attrs{AttrPort: "5555", AttrAuthMethod: "password", AttrInvalidUser: "true", AttrHost: "web01", AttrPID: "1234"},
+		},
+		{
+			line:   "2026-02-01T12:00:01Z web01 sshd[1234]: Failed password for root from 1.2.3.4 port 5556 ssh2",
+			action: "auth", status: "FAIL", user: "root", ip: "1.2.3.4",
+			attrs: Attrs{AttrPort: "5556", AttrAuthMethod: "password"},
+		},
+		{
+			line:   "2026-02-01T12:00:02Z web01 sshd[1235]: Accepted publickey for deploy from 10.0.0.5 port 40022 ssh2: ED25519 SHA256:abc",
+			action: "auth", status: "SUCCESS", user: "deploy", ip: "10.0.0.5",
+			attrs: Attrs{AttrPort: "40022", AttrAuthMethod: "publickey"},
+		},
+		{
+			line:   "2026-02-01T12:00:03Z web01 sshd[1236]: Disconnected from authenticating user root 1.2.3.4 port 5557 [preauth]",
+			action: "disconnect", status: "PREAUTH", user: "root", ip: "1.2.3.4",
+			attrs: Attrs{AttrPort: "5557"},
+		},
+		{
+			line:   "2026-02-01T12:00:04Z web01 sshd[1237]: Invalid user oracle from 1.2.3.4 port 5558",
+			action: "invalid_user", status: "FAIL", user: "oracle", ip: "1.2.3.4",
+			attrs: Attrs{AttrInvalidUser: "true"},
+		},
+		{
+			line:   "2026-02-01T12:00:05Z web01 sshd[1238]: message repeated 3 times: [ Failed password for root from 1.2.3.4 port 5559 ssh2]",
+			action: "auth", status: "FAIL", user: "root", ip: "1.2.3.4",
+			attrs: Attrs{AttrRepeated: "3"},
+		},
+		{
+			line:  "2026-02-01T12:00:06Z web01 sshd[1239]: Server listening on 0.0.0.0 port 22.",
+			attrs: Attrs{AttrMessage: "Server listening on 0.0.0.0 port 22."},
+		},
+	}
+	for _, tt := range tests {
+		ev, err := ParseSSHDLine(tt.line)
+		if err != nil {
+			t.Errorf("ParseSSHDLine(%q): %v", tt.line, err)
+			continue
+		}
+		if ev.Service != "ssh" || ev.Action != tt.action || ev.Status != tt.status || ev.User != tt.user || ev.IP != tt.ip {
+			t.Errorf("ParseSSHDLine(%q) = service=%s action=%s status=%s user=%s ip=%s, want ssh/%s/%s/%s/%s",
+				tt.line, ev.Service, ev.Action, ev.Status, ev.User, ev.IP, tt.action, tt.status, tt.user, tt.ip)
+		}
+		checkAttrs(t, ev, tt.attrs)
+	}
+}
+
+func TestParseSSHDLineClassicStamp(t *testing.T) {
+	ev, err := ParseSSHDLine("Feb  1 12:00:01 web01 sshd[1234]: Failed password for invalid user admin from 1.2.3.4 port 5555 ssh2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.TS.Month() != time.February || ev.TS.Day() != 1 || ev.TS.Hour() != 12 {
+		t.Errorf("TS = %v, want Feb 1 12:00:01", ev.TS)
+	}
+	if !ev.InvalidUser() || ev.AuthMethod() != "password" || ev.Port() != "5555" {
+		t.Errorf("accessors = %v %q %q", ev.InvalidUser(), ev.AuthMethod(), ev.Port())
+	}
+}
+
+func TestParseSSHDLineRejectsOtherPrograms(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"2026-02-01T12:00:01Z web01 sudo[1]: alice : TTY=pts/0 ; PWD=/ ; USER=root ; COMMAND=/bin/ls",
+		"not syslog at all",
+	} {
+		if ev, err := ParseSSHDLine(line); err == nil {
+			t.Errorf("ParseSSHDLine(%q) = %+v, want error", line, ev)
+		}
+	}
+}
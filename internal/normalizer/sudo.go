@@ -0,0 +1,71 @@
+package normalizer
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Attribute keys filled by the sudo parser.
+const (
+	AttrTargetUser = "target_user"
+	AttrCommand    = "command"
+	AttrTTY        = "tty"
+	AttrPWD        = "pwd"
+)
+
+// TargetUser is the account a sudo command ran as.
+func (e Event) TargetUser() string { return e.Attrs.Get(AttrTargetUser) }
+
+// Command is the command line of a sudo event.
+func (e Event) Command() string { return e.Attrs.Get(AttrCommand) }
+
+var (
+	// alice : 3 incorrect password attempts ; TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/bin/bash
+	sudoCommand = regexp.MustCompile(`^\s*(\S+) : (?:(.*?) ; )?TTY=(\S+) ; PWD=(.*?) ; USER=(\S+) ;(?: .*?;)? COMMAND=(.*)$`)
+	// pam_unix(sudo:auth): authentication failure; logname=alice uid=1000 euid=0 tty=/dev/pts/0 ruser=alice rhost=  user=alice
+	sudoPAMFail = regexp.MustCompile(`^pam_unix\(sudo:auth\): authentication failure;.*\bruser=(\S*)`)
+	// pam_unix(sudo:session): session opened for user root(uid=0) by alice(uid=1000)
+	sudoSession = regexp.MustCompile(`^pam_unix\(sudo:session\): session (opened|closed) for user (\w[\w.-]*)(?:\(uid=\d+\))?(?: by (\w[\w.-]*))?`)
+)
+
+// parseSudoBody maps sudo syslog messages: executed commands
+// (action=exec), failed authentication (action=auth status=FAIL) and
+// sessions (action=session).
+func parseSudoBody(ev *Event, msg string) error {
+	ev.Service = "sudo"
+	msg = strings.TrimSpace(msg)
+
+	if m := sudoCommand.FindStringSubmatch(msg); m != nil {
+		ev.User = m[1]
+		ev.Action = "exec"
+		ev.Status = "SUCCESS"
+		if m[2] != "" {
+			// "3 incorrect password attempts", "command not allowed", ...
+			ev.Status = "FAIL"
+			ev.Attrs[AttrReason] = m[2]
+			if strings.Contains(m[2], "password") {
+				ev.Action = "auth"
+			}
+		}
+		ev.Attrs[AttrTTY] = m[3]
+		ev.Attrs[AttrPWD] = m[4]
+		ev.Attrs[AttrTargetUser] = m[5]
+		ev.Attrs[AttrCommand] = m[6]
+		return nil
+	}
+	if m := sudoPAMFail.FindStringSubmatch(msg); m != nil {
+		ev.User = m[1]
+		ev.Action = "auth"
+		ev.Status = "FAIL"
+		return nil
+	}
+	if m := sudoSession.FindStringSubmatch(msg); m != nil {
+		ev.Action = "session"
+		ev.Status = strings.ToUpper(m[1])
+		ev.Attrs[AttrTargetUser] = m[2]
+		ev.User = m[3]
+		return nil
+	}
+	return errors.New("sudo: unrecognized message")
+}
@@ -0,0 +1,313 @@
+package normalizer
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Attribute keys filled from the syslog header.
+const (
+	AttrHost           = "host"
+	AttrProgram        = "program"
+	AttrPID            = "pid"
+	AttrFacility       = "facility"
+	AttrSyslogSeverity = "syslog_severity"
+	AttrMsgID          = "msgid"
+	AttrStructuredData = "structured_data"
+)
+
+// Host is the hostname from the syslog header.
+func (e Event) Host() string { return e.Attrs.Get(AttrHost) }
+
+// Program is the syslog tag / app-name ("sshd", "sudo", ...).
+func (e Event) Program() string { return e.Attrs.Get(AttrProgram) }
+
+// PID is the process id from the syslog header, if any.
+func (e Event) PID() string { return e.Attrs.Get(AttrPID) }
+
+// Facility is the syslog facility name ("auth", "authpriv", "local0", ...),
+// only set when the line carried a <PRI>.
+func (e Event) Facility() string { return e.Attrs.Get(AttrFacility) }
+
+// SyslogSeverity is the syslog level name ("err", "info", ...), only set
+// when the line carried a <PRI>. Not to be confused with alert severity.
+func (e Event) SyslogSeverity() string { return e.Attrs.Get(AttrSyslogSeverity) }
+
+// now is replaceable so year inference is deterministic.
+var now = time.Now
+
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+var severityNames = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+// syslogHeader is the part of a syslog line before the message.
+type syslogHeader struct {
+	TS       time.Time
+	Host     string
+	Program  string
+	PID      string
+	Facility string
+	Severity string
+	MsgID    string
+	SD       string
+}
+
+func (h syslogHeader) event(line string) Event {
+	ev := Event{
+		TS:      h.TS,
+		RawLine: line,
+		Attrs:   Attrs{AttrProgram: h.Program},
+	}
+	set := func(k, v string) {
+		if v != "" {
+			ev.Attrs[k] = v
+		}
+	}
+	set(AttrHost, h.Host)
+	set(AttrPID, h.PID)
+	set(AttrFacility, h.Facility)
+	set(AttrSyslogSeverity, h.Severity)
+	set(AttrMsgID, h.MsgID)
+	set(AttrStructuredData, h.SD)
+	return ev
+}
+
+// parseSyslogHeader splits a syslog line into header and message.
+// It accepts, with or without a leading <PRI>:
+//
+//	RFC 3164: Feb  1 12:00:01 host sshd[1234]: message
+//	RFC 3164 with ISO stamp (rsyslog): 2026-02-01T12:00:01.5+09:00 host sshd[1234]: message
+//	RFC 5424: <34>1 2026-02-01T12:00:01Z host sshd 1234 - - message
+func parseSyslogHeader(line string) (syslogHeader, string, error) {
+	var h syslogHeader
+	rest := line
+
+	if strings.HasPrefix(rest, "<") {
+		end := strings.IndexByte(rest, '>')
+		if end < 2 || end > 4 {
+			return h, "", errors.New("syslog: bad PRI")
+		}
+		pri, err := strconv.Atoi(rest[1:end])
+		if err != nil || pri > 191 {
+			return h, "", errors.New("syslog: bad PRI")
+		}
+		h.Facility = facilityNames[pri/8]
+		h.Severity = severityNames[pri%8]
+		rest = rest[end+1:]
+
+		if strings.HasPrefix(rest, "1 ") {
+			return parse5424(h, rest[2:])
+		}
+	}
+	return parse3164(h, rest)
+}
+
+func parse3164(h syslogHeader, rest string) (syslogHeader, string, error) {
+	const stamp = "Jan _2 15:04:05"
+
+	if tok, after, ok := strings.Cut(rest, " "); ok && len(tok) > 0 && tok[0] >= '0' && tok[0] <= '9' {
+		ts, err := time.Parse(time.RFC3339Nano, tok)
+		if err != nil {
+			return h, "", err
+		}
+		h.TS = ts
+		rest = after
+	} else {
+		if len(rest) < len(stamp)+1 {
+			return h, "", errors.New("syslog: line too short")
+		}
+		ts, err := time.ParseInLocation(stamp, rest[:len(stamp)], time.Local)
+		if err != nil {
+			return h, "", err
+		}
+		// The year is not in the line: assume the current year, or last
+		// year when that would put the line in the future.
+		ref := now()
+		ts = ts.AddDate(ref.Year(), 0, 0)
+		if ts.After(ref.Add(24 * time.Hour)) {
+			ts = ts.AddDate(-1, 0, 0)
+		}
+		h.TS = ts
+		rest = rest[len(stamp):]
+	}
+
+	rest = strings.TrimLeft(rest, " ")
+	host, rest, ok := strings.Cut(rest, " ")
+	if !ok || host == "" {
+		return h, "", errors.New("syslog: missing hostname")
+	}
+	h.Host = host
+
+	tag, msg, ok := strings.Cut(rest, ": ")
+	if !ok {
+		tag, ok = strings.CutSuffix(rest, ":")
+		if !ok {
+			return h, "", errors.New("syslog: missing tag")
+		}
+	}
+	if strings.ContainsAny(tag, " =") {
+		return h, "", errors.New("syslog: bad tag")
+	}
+	h.Program, h.PID = splitTag(tag)
+	return h, msg, nil
+}
+
+func parse5424(h syslogHeader, rest string) (syslogHeader, string, error) {
+	fields := make([]string, 0, 5)
+	for i := 0; i < 5; i++ {
+		f, after, ok := strings.Cut(rest, " ")
+		if !ok {
+			return h, "", errors.New("syslog: short RFC 5424 header")
+		}
+		if f == "-" {
+			f = ""
+		}
+		fields = append(fields, f)
+		rest = after
+	}
+	if fields[0] != "" {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return h, "", err
+		}
+		h.TS = ts
+	} else {
+		h.TS = now()
+	}
+	h.Host, h.Program, h.PID, h.MsgID = fields[1], fields[2], fields[3], fields[4]
+
+	sd, msg, err := cutStructuredData(rest)
+	if err != nil {
+		return h, "", err
+	}
+	h.SD = sd
+	msg = strings.TrimPrefix(msg, "\ufeff") // UTF-8 BOM
+	return h, msg, nil
+}
+
+// cutStructuredData splits "[id k="v"][id2 ...] msg" or "- msg".
+func cutStructuredData(s string) (sd, msg string, err error) {
+	if s == "-" || strings.HasPrefix(s, "- ") {
+		return "", strings.TrimPrefix(s[1:], " "), nil
+	}
+	i := 0
+	for i < len(s) && s[i] == '[' {
+		inQuote := false
+		for i++; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' {
+				i++
+				continue
+			}
+			if c == '"' {
+				inQuote = !inQuote
+			} else if c == ']' && !inQuote {
+				i++
+				break
+			}
+		}
+	}
+	if i == 0 {
+		return "", "", errors.New("syslog: bad structured data")
+	}
+	return s[:i], strings.TrimPrefix(s[i:], " "), nil
+}
+
+// splitTag splits "sshd[1234]" into ("sshd", "1234").
+func splitTag(tag string) (program, pid string) {
+	if i := strings.IndexByte(tag, '['); i >= 0 && strings.HasSuffix(tag, "]") {
+		return tag[:i], tag[i+1 : len(tag)-1]
+	}
+	return tag, ""
+}
+
+// BodyParser fills ev (header attributes and TS already set) from the
+// syslog message of one program. Returning an error makes the
+// SyslogParser fall back to a generic event.
+type BodyParser func(ev *Event, msg string) error
+
+// SyslogParser strips the syslog envelope and dispatches the message to
+// the BodyParser registered for the program.
+type SyslogParser struct {
+	programs map[string]BodyParser
+}
+
+// NewSyslogParser returns a parser with the built-in body parsers
+// (sshd, sudo, nginx) registered.
+func NewSyslogParser() *SyslogParser {
+	p := &SyslogParser{programs: make(map[string]BodyParser)}
+	p.Register("sshd", parseSSHDBody)
+	p.Register("sudo", parseSudoBody)
+	p.Register("nginx", parseNginxBody)
+	return p
+}
+
+// Register binds a body parser to a program name, replacing any
+// previous one.
+func (p *SyslogParser) Register(program string, bp BodyParser) {
+	p.programs[program] = bp
+}
+
+// Parse parses one syslog line. Programs without a body parser (or whose
+// parser fails) yield a generic event: service=<program>, msg=<message>.
+func (p *SyslogParser) Parse(line string) (Event, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return Event{}, errors.New("empty line")
+	}
+	h, msg, err := parseSyslogHeader(line)
+	if err != nil {
+		return Event{}, err
+	}
+
+	if bp, ok := p.programs[h.Program]; ok {
+		ev := h.event(line)
+		if err := bp(&ev, msg); err == nil {
+			return ev, nil
+		}
+	}
+
+	ev := h.event(line)
+	ev.Service = h.Program
+	if ev.Service == "" {
+		ev.Service = "syslog"
+	}
+	ev.Attrs[AttrMessage] = msg
+	return ev, nil
+}
+
+var defaultSyslog = NewSyslogParser()
+
+// ParseSyslog parses a syslog line with the built-in body parsers.
+func ParseSyslog(line string) (Event, error) {
+	return defaultSyslog.Parse(line)
+}
+
+func parseSSHDBody(ev *Event, msg string) error {
+	ev.Service = "ssh"
+	fillSSHD(ev, msg)
+	return nil
+}
+
+// parseNginxBody handles nginx "access_log syslog:..." output; the access
+// line carries its own timestamp.
+func parseNginxBody(ev *Event, msg string) error {
+	web, err := ParseAccessLine(msg)
+	if err != nil {
+		return err
+	}
+	for k, v := range ev.Attrs {
+		web.Attrs[k] = v
+	}
+	web.RawLine = ev.RawLine
+	*ev = web
+	return nil
+}
@@ -0,0 +1,155 @@
+package normalizer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fixNow pins the reference time used for RFC 3164 year inference.
+func fixNow(t *testing.T, ref time.Time) {
+	t.Helper()
+	old := now
+	now = func() time.Time { return ref }
+	t.Cleanup(func() { now = old })
+}
+
+func TestParseSyslog(t *testing.T) {
+	fixNow(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local))
+	tests := []struct {
+		name    string
+		line    string
+		ts      time.Time
+		service string
+		action  string
+		status  string
+		user    string
+		ip      string
+		attrs   Attrs
+	}{
+		{
+			name:    "3164 sshd",
+			line:    "Feb  1 12:00:01 web01 sshd[1234]: Failed password for invalid user admin from 1.2.3.4 port 5555 ssh2",
+			ts:      time.Date(2026, 2, 1, 12, 0, 1, 0, time.Local),
+			service: "ssh", action: "auth", status: "FAIL", user: "admin", ip: "1.2.3.4",
+			attrs: Attrs{AttrHost: "web01", AttrProgram: "sshd", AttrPID: "1234", AttrPort: "5555"},
+		},
+		{
+			name:    "3164 with PRI",
+			line:    "<38>Feb 11 08:30:00 web01 sshd[77]: Accepted publickey for deploy from 10.0.0.5 port 40022 ssh2",
+			ts:      time.Date(2026, 2, 11, 8, 30, 0, 0, time.Local),
+			service: "ssh", action: "auth", status: "SUCCESS", user: "deploy", ip: "10.0.0.5",
+			attrs: Attrs{AttrFacility: "auth", AttrSyslogSeverity: "info"},
+		},
+		{
+			name:    "3164 year rolls back",
+			line:    "Dec 31 23:59:59 web01 cron[9]: job done",
+			ts:      time.Date(2025, 12, 31, 23, 59, 59, 0, time.Local),
+			service: "cron",
+			attrs:   Attrs{AttrMessage: "job done"},
+		},
+		{
+			name:    "ISO stamp (rsyslog)",
+			line:    "2026-02-01T12:00:01.5+09:00 web01 sudo[42]: alice : TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/bin/bash",
+			ts:      time.Date(2026, 2, 1, 3, 0, 1, 5e8, time.UTC),
+			service: "sudo", action: "exec", status: "SUCCESS", user: "alice",
+			attrs: Attrs{AttrTargetUser: "root", AttrCommand: "/bin/bash", AttrTTY: "pts/0", AttrPWD: "/home/alice"},
+		},
+		{
+			name:    "sudo failed password",
+			line:    "Feb  1 12:00:01 web01 sudo[42]: alice : 3 incorrect password attempts ; TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/bin/bash",
+			ts:      time.Date(2026, 2, 1, 12, 0, 1, 0, time.Local),
+			service: "sudo", action: "auth", status: "FAIL", user: "alice",
+			attrs: Attrs{AttrReason: "3 incorrect password attempts"},
+		},
+		{
+			name:    "5424 sshd with structured data",
+			line:    `<34>1 2026-02-01T12:00:01Z web01 sshd 1234 ID47 [origin ip="10.0.0.1"] Invalid user oracle from 1.2.3.4 port 5558`,
+			ts:      time.Date(2026, 2, 1, 12, 0, 1, 0, time.UTC),
+			service: "ssh", action: "invalid_user", status: "FAIL", user: "oracle", ip: "1.2.3.4",
+			attrs: Attrs{AttrFacility: "auth", AttrSyslogSeverity: "crit", AttrMsgID: "ID47", AttrStructuredData: `[origin ip="10.0.0.1"]`, AttrPID: "1234"},
+		},
+		{
+			name:    "5424 nginx access line",
+			line:    `<190>1 2026-02-01T12:00:05Z web01 nginx - - - 203.0.113.7 - - [01/Feb/2026:12:00:01 +0000] "GET /.git/config HTTP/1.1" 404 153 "-" "curl/8.0"`,
+			ts:      time.Date(2026, 2, 1, 12, 0, 1, 0, time.UTC),
+			service: "web", status: "404", ip: "203.0.113.7",
+			attrs: Attrs{AttrFacility: "local7", AttrSyslogSeverity: "info", AttrHost: "web01", AttrProgram: "nginx"},
+		},
+		{
+			name:    "unknown program is generic",
+			line:    "<13>1 2026-02-01T12:00:01Z web01 myapp - - - started worker 3",
+			ts:      time.Date(2026, 2, 1, 12, 0, 1, 0, time.UTC),
+			service: "myapp",
+			attrs:   Attrs{AttrFacility: "user", AttrSyslogSeverity: "notice", AttrMessage: "started worker 3"},
+		},
+		{
+			name:    "body parser failure falls back",
+			line:    "Feb  1 12:00:01 web01 sudo[42]: something unusual",
+			ts:      time.Date(2026, 2, 1, 12, 0, 1, 0, time.Local),
+			service: "sudo",
+			attrs:   Attrs{AttrMessage: "something unusual"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := ParseSyslog(tt.line)
+			if err != nil {
+				t.Fatalf("ParseSyslog(%q): %v", tt.line, err)
+			}
+			if !ev.TS.Equal(tt.ts) {
+				t.Errorf("TS = %v, want %v", ev.TS, tt.ts)
+			}
+			if ev.Service != tt.service || ev.Action != tt.action || ev.Status != tt.status || ev.User != tt.user || ev.IP != tt.ip {
+				t.Errorf("service=%s action=%s status=%s user=%s ip=%s, want %s/%s/%s/%s/%s",
+					ev.Service, ev.Action, ev.Status, ev.User, ev.IP, tt.service, tt.action, tt.status, tt.user, tt.ip)
+			}
+			if ev.RawLine != tt.line {
+				t.Errorf("RawLine = %q", ev.RawLine)
+			}
+			checkAttrs(t, ev, tt.attrs)
+		})
+	}
+}
+
+func TestSyslogParserRegister(t *testing.T) {
+	p := NewSyslogParser()
+	p.Register("myapp", func(ev *Event, msg string) error {
+		if msg != "login failed" {
+			return errors.New("no match")
+		}
+		ev.Service, ev.Action, ev.Status = "app", "login", "FAIL"
+		return nil
+	})
+
+	ev, err := p.Parse("2026-02-01T12:00:01Z web01 myapp[5]: login failed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Service != "app" || ev.Action != "login" || ev.Status != "FAIL" || ev.PID() != "5" {
+		t.Errorf("got %+v", ev)
+	}
+
+	ev, err = p.Parse("2026-02-01T12:00:01Z web01 myapp[5]: hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Service != "myapp" || ev.Attr(AttrMessage) != "hello" {
+		t.Errorf("fallback got %+v", ev)
+	}
+}
+
+func TestParseSyslogErrors(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"<999>Feb  1 12:00:01 web01 sshd[1]: x",
+		"<34>1 2026-02-01T12:00:01Z web01",
+		"Feb  1 12:00:01 web01",
+		"2026-02-01T12:00:01Z service=auth user=alice",
+		"not syslog",
+	} {
+		if ev, err := ParseSyslog(line); err == nil {
+			t.Errorf("ParseSyslog(%q) = %+v, want error", line, ev)
+		}
+	}
+}
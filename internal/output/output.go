@@ -0,0 +1,223 @@
+// Package output writes alerts (and optionally normalized events) as
+// text, NDJSON, a JSON report (the report.json schema) or CSV.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+	"go-logshield/internal/normalizer"
+)
+
+// Output formats.
+const (
+	Text   = "text"   // format.Message, for people
+	NDJSON = "ndjson" // one JSON object per line with a "type" field
+	JSON   = "json"   // one array of format.Record, written on Close
+	CSV    = "csv"    // one row per alert/event with a "type" column
+)
+
+// Formats lists the supported formats.
+var Formats = []string{Text, NDJSON, JSON, CSV}
+
+// Valid reports whether name is a supported format.
+func Valid(name string) bool {
+	for _, f := range Formats {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Options tune a Writer.
+type Options struct {
+	// Events also writes normalized events. NDJSON and CSV interleave
+	// them with the alerts; JSON embeds each alert's contributing events
+	// in its record so the file stays a report.json array.
+	Events bool
+}
+
+// Writer receives the events and alerts of one run in order.
+type Writer interface {
+	Event(ev normalizer.Event) error
+	Alert(a detector.Alert) error
+	// Close flushes buffered output; it does not close the io.Writer.
+	Close() error
+}
+
+// New returns a Writer for the named format.
+func New(name string, w io.Writer, opts Options) (Writer, error) {
+	switch name {
+	case Text, "":
+		return &textWriter{w: w, opts: opts}, nil
+	case NDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w), opts: opts}, nil
+	case JSON:
+		return &jsonWriter{w: w, opts: opts, records: []format.Record{}}, nil
+	case CSV:
+		cw := &csvWriter{w: csv.NewWriter(w), opts: opts}
+		return cw, cw.w.Write(csvHeader)
+	}
+	return nil, fmt.Errorf("unknown output format %q (want %s)", name, strings.Join(Formats, ", "))
+}
+
+// EventLine is the one-line debug form of an event.
+func EventLine(ev normalizer.Event) string {
+	return fmt.Sprintf(
+		"%s service=%s action=%s user=%s ip=%s status=%s path=%s",
+		ev.TS.Format("15:04:05"),
+		ev.Service,
+		ev.Action,
+		ev.User,
+		ev.IP,
+		ev.Status,
+		ev.Path,
+	)
+}
+
+type textWriter struct {
+	w    io.Writer
+	opts Options
+}
+
+func (t *textWriter) Event(ev normalizer.Event) error {
+	if !t.opts.Events {
+		return nil
+	}
+	_, err := fmt.Fprintln(t.w, EventLine(ev))
+	return err
+}
+
+func (t *textWriter) Alert(a detector.Alert) error {
+	_, err := fmt.Fprintln(t.w, format.Message(a))
+	return err
+}
+
+func (t *textWriter) Close() error { return nil }
+
+type ndjsonWriter struct {
+	enc  *json.Encoder
+	opts Options
+}
+
+func (n *ndjsonWriter) Event(ev normalizer.Event) error {
+	if !n.opts.Events {
+		return nil
+	}
+	return n.enc.Encode(struct {
+		Type string `json:"type"`
+		format.EventRecord
+	}{"event", format.ToEventRecord(ev)})
+}
+
+func (n *ndjsonWriter) Alert(a detector.Alert) error {
+	return n.enc.Encode(struct {
+		Type string `json:"type"`
+		format.Record
+	}{recordType(a), format.ToRecord(a)})
+}
+
+// recordType is the "type" of an alert row: "summary" for the closing
+// alert of a suppressed incident, "alert" otherwise.
+func recordType(a detector.Alert) string {
+	if a.Summary {
+		return "summary"
+	}
+	return "alert"
+}
+
+func (n *ndjsonWriter) Close() error { return nil }
+
+type jsonWriter struct {
+	w       io.Writer
+	opts    Options
+	records []format.Record
+}
+
+func (j *jsonWriter) Event(normalizer.Event) error { return nil }
+
+func (j *jsonWriter) Alert(a detector.Alert) error {
+	r := format.ToRecord(a)
+	if j.opts.Events {
+		for _, ev := range a.Events {
+			r.Events = append(r.Events, format.ToEventRecord(ev))
+		}
+	}
+	j.records = append(j.records, r)
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	b, err := json.MarshalIndent(j.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = j.w.Write(append(b, '\n'))
+	return err
+}
+
+var csvHeader = []string{
+	"type", "ts", "severity", "rule_id", "title", "key", "count", "window",
+	"first_seen", "last_seen", "service", "action", "user", "ip", "status", "path", "related",
+	"suppressed",
+}
+
+type csvWriter struct {
+	w    *csv.Writer
+	opts Options
+}
+
+func (c *csvWriter) Event(ev normalizer.Event) error {
+	if !c.opts.Events {
+		return nil
+	}
+	return c.w.Write([]string{
+		"event", csvTime(ev.TS), "", "", "", "", "", "",
+		"", "", ev.Service, ev.Action, ev.User, ev.IP, ev.Status, ev.Path, "", "",
+	})
+}
+
+func (c *csvWriter) Alert(a detector.Alert) error {
+	r := format.ToRecord(a)
+	return c.w.Write([]string{
+		recordType(a), csvTime(r.TS), r.Severity, r.RuleID, r.Title, r.Key,
+		strconv.Itoa(r.Count), r.Window.String(),
+		csvTime(r.FirstSeen), csvTime(r.LastSeen), r.Service, "", "", r.IP, "", "",
+		csvRelated(r.Related), strconv.Itoa(r.Suppressed),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// csvRelated flattens Related as "ips=a|b;users=c", keys sorted.
+func csvRelated(rel map[string][]string) string {
+	keys := make([]string, 0, len(rel))
+	for k := range rel {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+strings.Join(rel[k], "|"))
+	}
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/normalizer"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden")
+
+var t0 = time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+
+// run is a short session: two failed logins, a brute force alert over
+// them, and the closing summary of the same incident.
+func run() ([]normalizer.Event, []detector.Alert) {
+	evs := []normalizer.Event{
+		{TS: t0, Service: "auth", Action: "login", User: "alice", IP: "203.0.113.7", Status: "FAIL",
+			RawLine: "2026-02-01T12:00:00Z auth login FAIL user=alice ip=203.0.113.7"},
+		{TS: t0.Add(2 * time.Second), Service: "auth", Action: "login", User: "bob", IP: "203.0.113.7", Status: "FAIL",
+			RawLine: "2026-02-01T12:00:02Z auth login FAIL user=bob ip=203.0.113.7"},
+	}
+	alert := detector.Alert{
+		RuleID:    "BRUTE_FORCE_LOGIN",
+		Severity:  detector.SeverityHigh,
+		Key:       "203.0.113.7",
+		Count:     2,
+		Window:    20 * time.Second,
+		FirstSeen: evs[0].TS,
+		LastSeen:  evs[1].TS,
+		Events:    evs,
+		Related:   map[string][]string{detector.RelatedUsers: {"alice", "bob"}},
+	}
+	summary := alert
+	summary.Summary = true
+	summary.Count = 7
+	summary.Suppressed = 3
+	summary.LastSeen = t0.Add(90 * time.Second)
+	summary.Events = nil
+	return evs, []detector.Alert{alert, summary}
+}
+
+func TestWriters(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		opts   Options
+	}{
+		{"text", Text, Options{}},
+		{"text_events", Text, Options{Events: true}},
+		{"ndjson", NDJSON, Options{}},
+		{"ndjson_events", NDJSON, Options{Events: true}},
+		{"report", JSON, Options{}},
+		{"report_events", JSON, Options{Events: true}},
+		{"csv", CSV, Options{}},
+		{"csv_events", CSV, Options{Events: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := New(tt.format, &buf, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			evs, alerts := run()
+			for _, ev := range evs {
+				if err := w.Event(ev); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, a := range alerts {
+				if err := w.Alert(a); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output differs from %s (go test -update to rewrite):\n%s", golden, buf.Bytes())
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range append(Formats, "") {
+		if _, err := New(name, &bytes.Buffer{}, Options{}); err != nil {
+			t.Errorf("New(%q): %v", name, err)
+		}
+		if name != "" && !Valid(name) {
+			t.Errorf("Valid(%q) = false", name)
+		}
+	}
+	if _, err := New("xml", &bytes.Buffer{}, Options{}); err == nil {
+		t.Error("New(xml): want error")
+	}
+	if Valid("xml") {
+		t.Error("Valid(xml) = true")
+	}
+}
@@ -0,0 +1,291 @@
+// Package pipeline runs live detection: source goroutines (file tailers,
+// readers) parse lines and feed one bounded channel; a single goroutine
+// submits them to the detection stage (a serial engine, or a sharded one
+// whose shards each own their detectors), so detectors never see
+// concurrent calls.
+package pipeline
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/ingest"
+	"go-logshield/internal/normalizer"
+	"go-logshield/internal/rules"
+
+	"github.com/nxadm/tail"
+)
+
+// DefaultBuffer is the channel capacity between sources and detection.
+const DefaultBuffer = 1024
+
+// Options are the pipeline callbacks. They run one at a time: OnEvent and
+// OnAlert in event order from the stage's Sink (the collector goroutine of
+// a sharded stage), OnError from Run as errors are read, so an error may
+// be reported before the alerts of lines read ahead of it. A slow callback
+// slows the sources down (backpressure) rather than growing a queue.
+type Options struct {
+	// Buffer is the capacity of the source -> detection channel.
+	Buffer int
+
+	// OnEvent also gets the events the stage dropped as too late
+	// (rules.Stage.Late counts them).
+	OnEvent func(ev normalizer.Event)      // every parsed event
+	OnAlert func(a detector.Alert)         // every alert
+	OnError func(source string, err error) // parse and read errors
+}
+
+type Pipeline struct {
+	registry *normalizer.Registry
+	opts     Options
+
+	items   chan ingest.Item
+	sources sync.WaitGroup
+	// callbacks serializes the Options callbacks; the Sink may run on
+	// another goroutine than Run.
+	callbacks sync.Mutex
+}
+
+func New(registry *normalizer.Registry, opts Options) *Pipeline {
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultBuffer
+	}
+	return &Pipeline{
+		registry: registry,
+		opts:     opts,
+		items:    make(chan ingest.Item, opts.Buffer),
+	}
+}
+
+// send blocks while the channel is full; false means ctx is done.
+func (p *Pipeline) send(ctx context.Context, it ingest.Item) bool {
+	select {
+	case p.items <- it:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *Pipeline) sendLine(ctx context.Context, src *normalizer.Source, line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
+	ev, err := src.Parse(line)
+	return p.send(ctx, ingest.Item{Source: src.Name(), Line: line, Event: ev, Err: err})
+}
+
+// Tail follows path (like tail -F) until ctx is done. Add every source
+// before calling Run.
+func (p *Pipeline) Tail(ctx context.Context, path string) {
+	p.tail(ctx, path, 0)
+}
+
+// TailAll follows every path like Tail, but first reads what the files
+// already hold as one stream merged in timestamp order (ingest.Merger
+// with lookahead lines per file). Tailing each file from the start on its
+// own goroutine would let whichever backlog is read first push the
+// watermark past the others' events. Add every source before calling Run.
+func (p *Pipeline) TailAll(ctx context.Context, paths []string, lookahead int) {
+	p.sources.Add(1)
+	go func() {
+		defer p.sources.Done()
+
+		streams := make([]ingest.Stream, 0, len(paths))
+		offsets := make([]int64, len(paths))
+		for i, path := range paths {
+			fp, err := os.Open(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // tail이 생길 때까지 기다림
+			}
+			if err == nil {
+				defer fp.Close()
+				offsets[i], err = backlogEnd(fp)
+			}
+			if err != nil {
+				if !p.send(ctx, ingest.Item{Source: path, Err: &ingest.ReadError{Source: path, Err: err}}) {
+					return
+				}
+				continue
+			}
+			r := io.NewSectionReader(fp, 0, offsets[i])
+			streams = append(streams, ingest.NewLineStream(r, p.registry.Source(path)))
+		}
+
+		m := ingest.NewMerger(lookahead, streams...)
+		for {
+			it, ok := m.Next()
+			if !ok {
+				break
+			}
+			if !p.send(ctx, it) {
+				return
+			}
+		}
+
+		// 기존 내용을 다 보낸 뒤에 이어서 따라감
+		for i, path := range paths {
+			p.tail(ctx, path, offsets[i])
+		}
+	}()
+}
+
+// backlogEnd is the offset just past the last complete line of f; a line
+// still being written is left to the tailer.
+func backlogEnd(f *os.File) (int64, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 4096)
+	for end := st.Size(); end > 0; {
+		n := min(int64(len(buf)), end)
+		if _, err := f.ReadAt(buf[:n], end-n); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			return end - n + int64(i) + 1, nil
+		}
+		end -= n
+	}
+	return 0, nil
+}
+
+// tail follows path from offset.
+func (p *Pipeline) tail(ctx context.Context, path string, offset int64) {
+	p.sources.Add(1)
+	go func() {
+		defer p.sources.Done()
+
+		// Windows에서도 잘 따라가게 Poll + ReOpen 권장
+		t, err := tail.TailFile(path, tail.Config{
+			Location:  &tail.SeekInfo{Offset: offset, Whence: io.SeekStart},
+			Follow:    true,
+			ReOpen:    true,
+			MustExist: false,
+			Poll:      true,
+			Logger:    tail.DiscardingLogger,
+		})
+		if err != nil {
+			p.send(ctx, ingest.Item{Source: path, Err: &ingest.ReadError{Source: path, Err: fmt.Errorf("tail: %w", err)}})
+			return
+		}
+		defer t.Cleanup()
+		defer t.Stop()
+
+		src := p.registry.Source(path)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case line, ok := <-t.Lines:
+				if !ok {
+					return
+				}
+				if line == nil {
+					continue
+				}
+				if line.Err != nil {
+					if !p.send(ctx, ingest.Item{Source: path, Err: &ingest.ReadError{Source: path, Err: line.Err}}) {
+						return
+					}
+					continue
+				}
+				if !p.sendLine(ctx, src, line.Text) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Read feeds the lines of r (e.g. stdin) as source name until EOF or
+// ctx is done. Add every source before calling Run.
+func (p *Pipeline) Read(ctx context.Context, name string, r io.Reader) {
+	p.sources.Add(1)
+	go func() {
+		defer p.sources.Done()
+		src := p.registry.Source(name)
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			if !p.sendLine(ctx, src, sc.Text()) {
+				return
+			}
+		}
+		if err := sc.Err(); err != nil {
+			p.send(ctx, ingest.Item{Source: name, Err: &ingest.ReadError{Source: name, Err: err}})
+		}
+	}()
+}
+
+// Feed sends the items of s (e.g. an ingest.Merger over whole files, so
+// they arrive in timestamp order) until it ends or ctx is done. Add every
+// source before calling Run.
+func (p *Pipeline) Feed(ctx context.Context, s ingest.Stream) {
+	p.sources.Add(1)
+	go func() {
+		defer p.sources.Done()
+		for {
+			it, ok := s.Next()
+			if !ok || !p.send(ctx, it) {
+				return
+			}
+		}
+	}()
+}
+
+// Sink is the rules.Sink to build the detection stage with.
+func (p *Pipeline) Sink(ev normalizer.Event, alerts []detector.Alert) {
+	p.callbacks.Lock()
+	defer p.callbacks.Unlock()
+	if p.opts.OnEvent != nil && !ev.TS.IsZero() {
+		p.opts.OnEvent(ev)
+	}
+	if p.opts.OnAlert != nil {
+		for _, a := range alerts {
+			p.opts.OnAlert(a)
+		}
+	}
+}
+
+// Run submits items to stage (built with p.Sink) until ctx is done
+// (ctx.Err()) or every source has ended (nil). The stage is closed, and
+// so drained, before Run returns.
+func (p *Pipeline) Run(ctx context.Context, stage rules.Stage) error {
+	defer stage.Close()
+
+	go func() {
+		p.sources.Wait()
+		close(p.items)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case it, ok := <-p.items:
+			if !ok {
+				return nil
+			}
+			if it.Err != nil {
+				if p.opts.OnError != nil {
+					p.callbacks.Lock()
+					p.opts.OnError(it.Source, it.Err)
+					p.callbacks.Unlock()
+				}
+				continue
+			}
+			stage.Submit(it.Event)
+		}
+	}
+}
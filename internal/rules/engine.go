@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/normalizer"
+)
+
+// Engine runs a set of detectors over each event. It keeps the event-time
+// watermark: events older than the allowed lateness are dropped (and
+// counted) before they reach the detectors. An Engine and its detectors
+// are not safe for concurrent use; internal/pipeline gives it a single
+// goroutine.
+type Engine struct {
+	detectors []detector.Detector
+	clock     detector.Clock
+}
+
+// NewEngine runs ds (compiled rules and hand-written detectors) in order.
+func NewEngine(ds ...detector.Detector) *Engine {
+	return &Engine{detectors: ds}
+}
+
+// SetLateness sets how far behind the newest event an event may be and
+// still be processed.
+func (e *Engine) SetLateness(d time.Duration) { e.clock.Lateness = d }
+
+// Watermark is the current event-time watermark.
+func (e *Engine) Watermark() time.Time { return e.clock.Watermark() }
+
+// Late is the number of events dropped as too late.
+func (e *Engine) Late() uint64 { return e.clock.Late() }
+
+// DetectorStats is the state size of one detector.
+type DetectorStats struct {
+	RuleID string
+	detector.StateStats
+}
+
+// Stats reports the state size of every detector that keeps per-key
+// state, in detector order.
+func (e *Engine) Stats() []DetectorStats {
+	var out []DetectorStats
+	for _, d := range e.detectors {
+		if r, ok := d.(detector.StateReporter); ok {
+			out = append(out, DetectorStats{RuleID: d.RuleID(), StateStats: r.StateStats()})
+		}
+	}
+	return out
+}
+
+func (e *Engine) Detectors() []detector.Detector {
+	return e.detectors
+}
+
+// Process feeds ev to every detector and returns the alerts raised, in
+// detector order. Summaries a detector releases (detector.Flusher) come
+// before the alert of ev from the same detector.
+func (e *Engine) Process(ev normalizer.Event) []detector.Alert {
+	if !e.clock.Observe(ev.TS) {
+		return nil
+	}
+	wm := e.clock.Watermark()
+
+	var out []detector.Alert
+	for _, d := range e.detectors {
+		if w, ok := d.(detector.Watermarker); ok {
+			w.Advance(wm)
+		}
+		if f, ok := d.(detector.Flusher); ok {
+			out = append(out, f.Flush(wm)...)
+		}
+		if a, ok := d.Process(ev); ok {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// Flush releases every alert still held back (open incidents); call it
+// at end of input.
+func (e *Engine) Flush() []detector.Alert {
+	var out []detector.Alert
+	for _, d := range e.detectors {
+		if f, ok := d.(detector.Flusher); ok {
+			out = append(out, f.Flush(time.Time{})...)
+		}
+	}
+	return out
+}
@@ -0,0 +1,187 @@
+package rules
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed defaults/*.yaml
+var defaultFS embed.FS
+
+// Parse reads rules from YAML or JSON (JSON is valid YAML). A document
+// may hold a single rule or a list of rules; YAML files may contain
+// several documents separated by "---". Unknown fields are errors.
+func Parse(data []byte) ([]Rule, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var out []Rule
+	for {
+		var doc ruleDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, doc...)
+	}
+	return out, nil
+}
+
+// ruleDoc is one document: a single rule or a list of rules. It uses the
+// func-style unmarshaler because its unmarshal stays on the decoder (and
+// so keeps KnownFields); yaml.Node.Decode would not.
+type ruleDoc []Rule
+
+func (d *ruleDoc) UnmarshalYAML(unmarshal func(any) error) error {
+	var v any
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	if _, ok := v.([]any); ok {
+		var list []Rule
+		if err := unmarshal(&list); err != nil {
+			return err
+		}
+		*d = list
+		return nil
+	}
+	var r Rule
+	if err := unmarshal(&r); err != nil {
+		return err
+	}
+	*d = ruleDoc{r}
+	return nil
+}
+
+// LoadFile reads the rules in one file. An ID may appear only once.
+func LoadFile(path string) ([]Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := Parse(b)
+	if err == nil {
+		err = checkIDs(rs, path, map[string]string{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rs, nil
+}
+
+// checkIDs records the IDs of rs, read from file, in seen (ID -> file)
+// and fails on one already there.
+func checkIDs(rs []Rule, file string, seen map[string]string) error {
+	for _, r := range rs {
+		if prev, ok := seen[r.ID]; ok {
+			if prev == file {
+				return fmt.Errorf("duplicate rule id %q", r.ID)
+			}
+			return fmt.Errorf("duplicate rule id %q (also in %s)", r.ID, prev)
+		}
+		seen[r.ID] = file
+	}
+	return nil
+}
+
+// LoadDir reads every *.yaml, *.yml and *.json file in dir, in name order.
+// An ID may appear only once across the files; to replace a default rule,
+// reuse its ID (see Merge).
+func LoadDir(dir string) ([]Rule, error) {
+	return loadFS(os.DirFS(dir), dir)
+}
+
+// Defaults returns the built-in rules shipped in defaults/.
+func Defaults() ([]Rule, error) {
+	sub, err := fs.Sub(defaultFS, "defaults")
+	if err != nil {
+		return nil, err
+	}
+	return loadFS(sub, "defaults")
+}
+
+func loadFS(fsys fs.FS, name string) ([]Rule, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var out []Rule
+	seen := make(map[string]string)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch filepath.Ext(e.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		b, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(name, e.Name())
+		rs, err := Parse(b)
+		if err == nil {
+			err = checkIDs(rs, path, seen)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		out = append(out, rs...)
+	}
+	return out, nil
+}
+
+// Merge returns base with every rule in extra applied on top:
+// a rule with an existing ID replaces it, new IDs are appended.
+func Merge(base, extra []Rule) []Rule {
+	out := append([]Rule(nil), base...)
+	idx := make(map[string]int, len(out))
+	for i, r := range out {
+		idx[r.ID] = i
+	}
+	for _, r := range extra {
+		if i, ok := idx[r.ID]; ok {
+			out[i] = r
+			continue
+		}
+		idx[r.ID] = len(out)
+		out = append(out, r)
+	}
+	return out
+}
+
+// Load returns the default rules merged with the rules in dir.
+// A missing dir is not an error.
+func Load(dir string) ([]Rule, error) {
+	rs, err := Defaults()
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return rs, nil
+	}
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return rs, nil
+	}
+	extra, err := LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return Merge(rs, extra), nil
+}
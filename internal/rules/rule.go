@@ -0,0 +1,261 @@
+// Package rules loads declarative detection rules (YAML or JSON) and
+// compiles them into detectors.
+//
+// A rule file looks like:
+//
+//	id: BRUTE_FORCE_LOGIN
+//	title: 로그인 브루트포스 의심
+//	severity: high
+//	match:
+//	  - field: service
+//	    equals: auth
+//	  - field: status
+//	    in: [FAIL]
+//	  - field: reason
+//	    equals: bad_password
+//	group_by: ip
+//	window: 20s
+//	threshold: 5
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+	"go-logshield/internal/normalizer"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is one detection rule as written in a rule file.
+type Rule struct {
+	ID          string `yaml:"id"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	KeyLabel    string `yaml:"key_label"`
+	CountLabel  string `yaml:"count_label"`
+
+	Severity  detector.Severity `yaml:"severity"`
+	Match     []Condition       `yaml:"match"`
+	GroupBy   FieldList         `yaml:"group_by"`
+	Window    Duration          `yaml:"window"`
+	Threshold int               `yaml:"threshold"`
+
+	// MaxKeys caps the keys tracked at once (0 = unlimited); Eviction
+	// picks what to drop: "lru" (default) or "lowest_count".
+	MaxKeys  int    `yaml:"max_keys"`
+	Eviction string `yaml:"eviction"`
+
+	// Disabled rules are loaded (so they can override a default) but not compiled.
+	Disabled bool `yaml:"disabled"`
+}
+
+// Condition tests one event field: a typed field (service, action, user,
+// ip, status, path) or any parsed attribute (method, ua, reason, ...).
+// All set operators must hold; Not inverts the result.
+type Condition struct {
+	Field    string   `yaml:"field"`
+	Equals   *string  `yaml:"equals"`
+	In       []string `yaml:"in"`
+	Contains []string `yaml:"contains"` // any substring
+	Prefix   []string `yaml:"prefix"`   // any prefix
+	Regex    string   `yaml:"regex"`
+	Not      bool     `yaml:"not"`
+
+	re *regexp.Regexp
+}
+
+// FieldList accepts either a single field name or a list.
+type FieldList []string
+
+func (f *FieldList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*f = FieldList{n.Value}
+		return nil
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*f = list
+	return nil
+}
+
+// Duration accepts Go duration strings ("30s", "5m").
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(n *yaml.Node) error {
+	v, err := time.ParseDuration(n.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", n.Line, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Validate checks the rule and prepares its regexes.
+func (r *Rule) Validate() error {
+	if r.ID == "" {
+		return errors.New("rule: missing id")
+	}
+	if r.Severity == 0 {
+		return fmt.Errorf("rule %s: missing severity", r.ID)
+	}
+	if r.Window <= 0 {
+		return fmt.Errorf("rule %s: window must be > 0", r.ID)
+	}
+	if r.Threshold <= 0 {
+		return fmt.Errorf("rule %s: threshold must be > 0", r.ID)
+	}
+	if r.MaxKeys < 0 {
+		return fmt.Errorf("rule %s: max_keys must be >= 0", r.ID)
+	}
+	if _, err := detector.ParseEvictionPolicy(r.Eviction); err != nil {
+		return fmt.Errorf("rule %s: %w", r.ID, err)
+	}
+	if len(r.GroupBy) == 0 {
+		return fmt.Errorf("rule %s: missing group_by", r.ID)
+	}
+	for _, f := range r.GroupBy {
+		if f == "" {
+			return fmt.Errorf("rule %s: empty group_by field", r.ID)
+		}
+	}
+	for i := range r.Match {
+		c := &r.Match[i]
+		if c.Field == "" {
+			return fmt.Errorf("rule %s: condition without field", r.ID)
+		}
+		if c.Equals == nil && c.In == nil && c.Contains == nil && c.Prefix == nil && c.Regex == "" {
+			return fmt.Errorf("rule %s: condition on %q has no operator", r.ID, c.Field)
+		}
+		if c.Regex != "" {
+			re, err := regexp.Compile(c.Regex)
+			if err != nil {
+				return fmt.Errorf("rule %s: %w", r.ID, err)
+			}
+			c.re = re
+		}
+	}
+	return nil
+}
+
+func (c *Condition) match(ev normalizer.Event) bool {
+	v := ev.Field(c.Field)
+	ok := true
+	if c.Equals != nil {
+		ok = ok && v == *c.Equals
+	}
+	if c.In != nil {
+		ok = ok && anyOf(c.In, func(s string) bool { return v == s })
+	}
+	if c.Contains != nil {
+		ok = ok && anyOf(c.Contains, func(s string) bool { return strings.Contains(v, s) })
+	}
+	if c.Prefix != nil {
+		ok = ok && anyOf(c.Prefix, func(s string) bool { return strings.HasPrefix(v, s) })
+	}
+	if c.re != nil {
+		ok = ok && c.re.MatchString(v)
+	}
+	return ok != c.Not
+}
+
+func anyOf(list []string, f func(string) bool) bool {
+	for _, s := range list {
+		if f(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compile validates the rule and builds its detector. Its display text
+// is not registered; see Info.
+func (r Rule) Compile() (detector.Detector, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r.detector(), nil
+}
+
+// Compile validates every enabled rule once and returns a function that
+// builds a fresh detector for each of them, in order. Call it once per
+// engine (shard) that needs its own state.
+func Compile(rs []Rule) (func() []detector.Detector, error) {
+	var enabled []Rule
+	for _, r := range rs {
+		if r.Disabled {
+			continue
+		}
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+		enabled = append(enabled, r)
+	}
+	return func() []detector.Detector {
+		ds := make([]detector.Detector, 0, len(enabled))
+		for _, r := range enabled {
+			ds = append(ds, r.detector())
+		}
+		return ds
+	}, nil
+}
+
+// Info is the rule's display text for format.Register, on top of what
+// is registered for its ID so far; false when the rule sets no title.
+func (r Rule) Info() (format.RuleInfo, bool) {
+	if r.Title == "" {
+		return format.RuleInfo{}, false
+	}
+	info := format.Rule(r.ID)
+	info.Title = r.Title
+	if r.Description != "" {
+		info.Description = r.Description
+	}
+	if r.KeyLabel != "" {
+		info.KeyLabel = r.KeyLabel
+	}
+	if r.CountLabel != "" {
+		info.CountLabel = r.CountLabel
+	}
+	return info, true
+}
+
+// detector builds the detector of a validated rule.
+func (r Rule) detector() detector.Detector {
+	conds := r.Match
+	groupBy := r.GroupBy
+	policy, _ := detector.ParseEvictionPolicy(r.Eviction)
+	return detector.NewThresholdDetector(detector.ThresholdConfig{
+		RuleID:    r.ID,
+		Severity:  r.Severity,
+		Window:    time.Duration(r.Window),
+		Threshold: r.Threshold,
+		Limits:    detector.StateLimits{MaxKeys: r.MaxKeys, Policy: policy},
+		Match: func(ev normalizer.Event) bool {
+			for i := range conds {
+				if !conds[i].match(ev) {
+					return false
+				}
+			}
+			return true
+		},
+		GroupBy: func(ev normalizer.Event) string {
+			parts := make([]string, 0, len(groupBy))
+			for _, f := range groupBy {
+				v := ev.Field(f)
+				if v == "" {
+					return ""
+				}
+				parts = append(parts, v)
+			}
+			return strings.Join(parts, "|")
+		},
+	})
+}
@@ -0,0 +1,350 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+	"go-logshield/internal/normalizer"
+)
+
+const bruteForceYAML = `
+id: BRUTE_FORCE_LOGIN
+title: 로그인 브루트포스 의심
+severity: high
+match:
+  - field: service
+    equals: auth
+  - field: status
+    in: [FAIL]
+group_by: ip
+window: 20s
+threshold: 5
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		ids     []string
+		wantErr string
+	}{
+		{name: "single rule", data: bruteForceYAML, ids: []string{"BRUTE_FORCE_LOGIN"}},
+		{
+			name: "list",
+			data: "- {id: A, severity: low, group_by: ip, window: 1s, threshold: 1}\n- {id: B, severity: low, group_by: ip, window: 1s, threshold: 1}\n",
+			ids:  []string{"A", "B"},
+		},
+		{
+			name: "documents",
+			data: "id: A\nseverity: low\n---\n- id: B\n- id: C\n",
+			ids:  []string{"A", "B", "C"},
+		},
+		{
+			name: "json",
+			data: `{"id": "A", "severity": "critical", "group_by": ["user", "ip"], "window": "1m", "threshold": 3}`,
+			ids:  []string{"A"},
+		},
+		{name: "empty", data: "", ids: nil},
+		{name: "bad duration", data: "id: A\nwindow: 20 seconds\n", wantErr: "line 2"},
+		{name: "bad severity", data: "id: A\nseverity: urgent\n", wantErr: "urgent"},
+		{name: "bad yaml", data: "id: [A\n", wantErr: "yaml"},
+		{name: "unknown field", data: "id: A\nthresold: 3\n", wantErr: "thresold"},
+		{name: "unknown field in a list", data: "- id: A\n- id: B\n  windwo: 1s\n", wantErr: "line 3"},
+		{name: "unknown condition field", data: "id: A\nmatch:\n  - field: ip\n    equal: 1.2.3.4\n", wantErr: "equal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := Parse([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, r := range rs {
+				ids = append(ids, r.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.ids, ",") {
+				t.Errorf("ids = %q, want %q", ids, tt.ids)
+			}
+		})
+	}
+
+	rs, err := Parse([]byte(bruteForceYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rs[0]
+	if r.Severity != detector.SeverityHigh || time.Duration(r.Window) != 20*time.Second || r.Threshold != 5 ||
+		len(r.GroupBy) != 1 || r.GroupBy[0] != "ip" || len(r.Match) != 2 || *r.Match[0].Equals != "auth" {
+		t.Errorf("parsed %+v", r)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() Rule {
+		eq := "auth"
+		return Rule{ID: "R", Severity: detector.SeverityLow, GroupBy: FieldList{"ip"},
+			Window: Duration(time.Second), Threshold: 1, Match: []Condition{{Field: "service", Equals: &eq}}}
+	}
+	tests := []struct {
+		name    string
+		edit    func(r *Rule)
+		wantErr string
+	}{
+		{"valid", func(*Rule) {}, ""},
+		{"attribute field", func(r *Rule) { r.Match[0].Field = "method" }, ""},
+		{"missing id", func(r *Rule) { r.ID = "" }, "missing id"},
+		{"missing severity", func(r *Rule) { r.Severity = 0 }, "missing severity"},
+		{"zero window", func(r *Rule) { r.Window = 0 }, "window"},
+		{"zero threshold", func(r *Rule) { r.Threshold = 0 }, "threshold"},
+		{"negative max_keys", func(r *Rule) { r.MaxKeys = -1 }, "max_keys"},
+		{"bad eviction", func(r *Rule) { r.Eviction = "random" }, "random"},
+		{"missing group_by", func(r *Rule) { r.GroupBy = nil }, "group_by"},
+		{"empty group_by field", func(r *Rule) { r.GroupBy = FieldList{"ip", ""} }, "group_by"},
+		{"condition without field", func(r *Rule) { r.Match[0].Field = "" }, "without field"},
+		{"condition without operator", func(r *Rule) { r.Match[0].Equals = nil }, "no operator"},
+		{"bad regex", func(r *Rule) { r.Match[0].Regex = "(" }, "regexp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.edit(&r)
+			err := r.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	rs, err := Parse([]byte(`
+id: WEB_SCAN
+severity: medium
+match:
+  - field: service
+    equals: web
+  - field: path
+    prefix: [/admin, /.env]
+  - field: status
+    in: ["403", "404"]
+  - field: ua
+    regex: '(?i)nikto|sqlmap'
+  - field: ip
+    contains: ["10.0."]
+    not: true
+group_by: [ip, ua]
+window: 10s
+threshold: 3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := rs[0].Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.RuleID() != "WEB_SCAN" {
+		t.Errorf("RuleID = %q", d.RuleID())
+	}
+
+	hit := func(sec int, ip, path, status, ua string) normalizer.Event {
+		return normalizer.Event{TS: time.Date(2026, 2, 1, 12, 0, sec, 0, time.UTC), Service: "web",
+			IP: ip, Path: path, Status: status, Attrs: normalizer.Attrs{normalizer.AttrUserAgent: ua}}
+	}
+	tests := []struct {
+		name   string
+		events []normalizer.Event
+		alert  bool
+	}{
+		{"matching requests", []normalizer.Event{
+			hit(0, "1.2.3.4", "/admin", "404", "Nikto/2.5"),
+			hit(1, "1.2.3.4", "/.env", "403", "Nikto/2.5"),
+			hit(2, "1.2.3.4", "/admin/x", "404", "Nikto/2.5"),
+		}, true},
+		{"excluded by not", []normalizer.Event{
+			hit(0, "10.0.0.1", "/admin", "404", "Nikto"),
+			hit(1, "10.0.0.1", "/admin", "404", "Nikto"),
+			hit(2, "10.0.0.1", "/admin", "404", "Nikto"),
+		}, false},
+		{"grouped by ip and ua", []normalizer.Event{
+			hit(0, "1.2.3.4", "/admin", "404", "Nikto"),
+			hit(1, "1.2.3.4", "/admin", "404", "sqlmap"),
+			hit(2, "1.2.3.4", "/admin", "404", "Nikto"),
+		}, false},
+		{"status outside in", []normalizer.Event{
+			hit(0, "1.2.3.4", "/admin", "200", "Nikto"),
+			hit(1, "1.2.3.4", "/admin", "200", "Nikto"),
+			hit(2, "1.2.3.4", "/admin", "200", "Nikto"),
+		}, false},
+		{"path without prefix", []normalizer.Event{
+			hit(0, "1.2.3.4", "/index.html", "404", "Nikto"),
+			hit(1, "1.2.3.4", "/index.html", "404", "Nikto"),
+			hit(2, "1.2.3.4", "/index.html", "404", "Nikto"),
+		}, false},
+		{"missing group_by field", []normalizer.Event{
+			hit(0, "1.2.3.4", "/admin", "404", ""),
+			hit(1, "1.2.3.4", "/admin", "404", ""),
+			hit(2, "1.2.3.4", "/admin", "404", ""),
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := rs[0].Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got *detector.Alert
+			for _, ev := range tt.events {
+				if a, ok := d.Process(ev); ok {
+					got = &a
+				}
+			}
+			if (got != nil) != tt.alert {
+				t.Fatalf("alert = %v, want %v", got != nil, tt.alert)
+			}
+			if got != nil && (got.Key != "1.2.3.4|Nikto/2.5" || got.Count != 3) {
+				t.Errorf("alert key=%q count=%d", got.Key, got.Count)
+			}
+		})
+	}
+
+	bad := rs[0]
+	bad.Threshold = 0
+	if _, err := bad.Compile(); err == nil {
+		t.Error("Compile of an invalid rule: want error")
+	}
+}
+
+func TestInfo(t *testing.T) {
+	r := Rule{ID: "INFO_TEST", Title: "제목", KeyLabel: "계정", Severity: detector.SeverityLow,
+		GroupBy: FieldList{"user"}, Window: Duration(time.Second), Threshold: 1}
+	if _, err := r.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if got := format.Rule("INFO_TEST").Title; got != "INFO_TEST" {
+		t.Errorf("Compile registered title %q", got)
+	}
+
+	info, ok := r.Info()
+	if !ok || info.Title != "제목" || info.KeyLabel != "계정" || info.CountLabel != "횟수" {
+		t.Errorf("Info = %+v, %v", info, ok)
+	}
+	r.Title = ""
+	if _, ok := r.Info(); ok {
+		t.Error("Info without a title: want false")
+	}
+}
+
+func TestLoadDuplicateIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		ids     string
+		wantErr string
+	}{
+		{
+			name: "distinct ids in name order",
+			files: fstest.MapFS{
+				"b.yaml":   {Data: []byte("id: B\n")},
+				"a.yml":    {Data: []byte("- id: A1\n- id: A2\n")},
+				"c.json":   {Data: []byte(`{"id": "C"}`)},
+				"notes.md": {Data: []byte("id: A1\n")},
+			},
+			ids: "A1,A2,B,C",
+		},
+		{
+			name: "same id in two files",
+			files: fstest.MapFS{
+				"a.yaml": {Data: []byte("id: X\n")},
+				"b.yaml": {Data: []byte("id: Y\n---\nid: X\n")},
+			},
+			wantErr: `rules/b.yaml: duplicate rule id "X" (also in rules/a.yaml)`,
+		},
+		{
+			name:    "same id twice in one file",
+			files:   fstest.MapFS{"a.yaml": {Data: []byte("- id: X\n- id: X\n")}},
+			wantErr: `rules/a.yaml: duplicate rule id "X"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := loadFS(tt.files, "rules")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("loadFS error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, r := range rs {
+				ids = append(ids, r.ID)
+			}
+			if got := strings.Join(ids, ","); got != tt.ids {
+				t.Errorf("ids = %s, want %s", got, tt.ids)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "dup.yaml")
+	if err := os.WriteFile(path, []byte("id: X\n---\nid: X\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), "duplicate rule id") {
+		t.Errorf("LoadFile error = %v, want duplicate rule id", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := []Rule{{ID: "A", Threshold: 1}, {ID: "B", Threshold: 1}}
+	extra := []Rule{{ID: "B", Threshold: 9}, {ID: "C", Threshold: 1}, {ID: "A", Disabled: true}}
+	got := Merge(base, extra)
+
+	want := []Rule{{ID: "A", Disabled: true}, {ID: "B", Threshold: 9}, {ID: "C", Threshold: 1}}
+	if len(got) != len(want) {
+		t.Fatalf("Merge = %+v", got)
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Threshold != want[i].Threshold || got[i].Disabled != want[i].Disabled {
+			t.Errorf("Merge[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if base[1].Threshold != 1 {
+		t.Error("Merge modified base")
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	rs, err := Defaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) == 0 {
+		t.Fatal("no default rules")
+	}
+	for _, r := range rs {
+		if _, err := r.Compile(); err != nil {
+			t.Errorf("default rule %s: %v", r.ID, err)
+		}
+	}
+}
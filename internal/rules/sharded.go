@@ -0,0 +1,247 @@
+package rules
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/normalizer"
+)
+
+// tickEvery is how often (in events) the watermark is broadcast to every
+// shard, so shards that see no traffic still evict old state. Engines
+// with a detector.Flusher broadcast it with every event instead.
+const tickEvery = 1024
+
+// ShardedEngine spreads detection over N worker goroutines. Every shard
+// owns its own instance of each detector; an event is sent, per
+// detector, to the shard that owns its key (detector.Keyed), so one
+// key's state always lives on one shard and sees events in order.
+// Detectors that are not Keyed run on shard 0. Results are put back in
+// submission order before they reach the Sink, so the output does not
+// depend on goroutine scheduling and matches Engine.Process.
+type ShardedEngine struct {
+	shards []*shard
+	keyers []detector.Keyed // per detector index; nil = shard 0
+	flush  bool             // some detector holds alerts back (detector.Flusher)
+	clock  detector.Clock
+	sink   Sink
+
+	futures   chan future
+	collected sync.WaitGroup
+	workers   sync.WaitGroup
+	sinceTick int
+}
+
+type shard struct {
+	detectors []detector.Detector
+	jobs      chan job
+}
+
+type job struct {
+	ev        normalizer.Event
+	wm        time.Time
+	detectors []int // indexes to run; nil to only advance and flush
+	res       chan<- []indexedAlert
+	final     bool // end of input: flush everything held back
+}
+
+type indexedAlert struct {
+	detector int
+	alert    detector.Alert
+}
+
+// future is one submitted event waiting for its n shard results.
+type future struct {
+	ev    normalizer.Event
+	n     int
+	res   chan []indexedAlert
+	final bool // the end-of-input flush; ev is zero
+}
+
+// NewShardedEngine builds n shards, calling build once per shard; every
+// call must return new detectors of the same kinds in the same order.
+func NewShardedEngine(n int, build func() []detector.Detector, sink Sink) *ShardedEngine {
+	if n < 1 {
+		n = 1
+	}
+	e := &ShardedEngine{
+		sink:    sink,
+		futures: make(chan future, n*tickEvery),
+	}
+	for i := 0; i < n; i++ {
+		e.shards = append(e.shards, &shard{detectors: build(), jobs: make(chan job, tickEvery)})
+	}
+	for _, d := range e.shards[0].detectors {
+		k, _ := d.(detector.Keyed)
+		e.keyers = append(e.keyers, k)
+		if _, ok := d.(detector.Flusher); ok {
+			e.flush = true
+		}
+	}
+
+	for _, sh := range e.shards {
+		e.workers.Add(1)
+		go func() {
+			defer e.workers.Done()
+			sh.run()
+		}()
+	}
+	e.collected.Add(1)
+	go func() {
+		defer e.collected.Done()
+		e.collect()
+	}()
+	return e
+}
+
+// SetLateness is as on Engine; call it before the first Submit.
+func (e *ShardedEngine) SetLateness(d time.Duration) { e.clock.Lateness = d }
+
+func (e *ShardedEngine) Late() uint64         { return e.clock.Late() }
+func (e *ShardedEngine) Watermark() time.Time { return e.clock.Watermark() }
+
+// Stats sums the state of each detector over the shards.
+func (e *ShardedEngine) Stats() []DetectorStats {
+	var out []DetectorStats
+	for i, d := range e.shards[0].detectors {
+		if _, ok := d.(detector.StateReporter); !ok {
+			continue
+		}
+		st := DetectorStats{RuleID: d.RuleID()}
+		for _, sh := range e.shards {
+			s := sh.detectors[i].(detector.StateReporter).StateStats()
+			st.Keys += s.Keys
+			st.Events += s.Events
+			st.Idle += s.Idle
+			st.Evicted += s.Evicted
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// Submit routes ev to its shards. It blocks when the shards or the
+// in-order collector fall behind.
+func (e *ShardedEngine) Submit(ev normalizer.Event) {
+	if !e.clock.Observe(ev.TS) {
+		e.futures <- future{ev: ev}
+		return
+	}
+	wm := e.clock.Watermark()
+
+	per := make(map[int][]int)
+	for i, k := range e.keyers {
+		s := 0
+		if k != nil {
+			key := k.Key(ev)
+			if key == "" {
+				continue
+			}
+			s = shardOf(key, len(e.shards))
+		}
+		per[s] = append(per[s], i)
+	}
+	// Engine.Process는 이벤트마다 모든 탐지기를 flush하므로, 보류 알림이 있을 수
+	// 있으면 이 이벤트를 받지 않는 샤드도 같은 워터마크로 flush해서 결과에 포함
+	if e.sinceTick++; e.flush || e.sinceTick >= tickEvery {
+		e.sinceTick = 0
+		for s := range e.shards {
+			if _, ok := per[s]; !ok {
+				per[s] = nil
+			}
+		}
+	}
+
+	res := make(chan []indexedAlert, len(per))
+	e.futures <- future{ev: ev, n: len(per), res: res}
+	for s, idx := range per {
+		e.shards[s].jobs <- job{ev: ev, wm: wm, detectors: idx, res: res}
+	}
+}
+
+// Close flushes the alerts held back on every shard, waits for
+// everything to reach the Sink and stops the workers.
+func (e *ShardedEngine) Close() {
+	res := make(chan []indexedAlert, len(e.shards))
+	e.futures <- future{n: len(e.shards), res: res, final: true}
+	for _, sh := range e.shards {
+		sh.jobs <- job{final: true, res: res}
+		close(sh.jobs)
+	}
+	close(e.futures)
+	e.workers.Wait()
+	e.collected.Wait()
+}
+
+func shardOf(key string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
+
+// run processes jobs in order. Every job advances and flushes all of the
+// shard's detectors before running the ones it names, as Engine.Process
+// does for each event.
+func (sh *shard) run() {
+	for j := range sh.jobs {
+		if !j.final {
+			for _, d := range sh.detectors {
+				if w, ok := d.(detector.Watermarker); ok {
+					w.Advance(j.wm)
+				}
+			}
+		}
+		var out []indexedAlert
+		for i, d := range sh.detectors {
+			if f, ok := d.(detector.Flusher); ok {
+				for _, a := range f.Flush(j.wm) {
+					out = append(out, indexedAlert{detector: i, alert: a})
+				}
+			}
+		}
+		for _, i := range j.detectors {
+			if a, ok := sh.detectors[i].Process(j.ev); ok {
+				out = append(out, indexedAlert{detector: i, alert: a})
+			}
+		}
+		j.res <- out
+	}
+}
+
+// collect hands results to the sink in submission order, alerts of one
+// event in detector order (as Engine.Process returns them).
+func (e *ShardedEngine) collect() {
+	for f := range e.futures {
+		var all []indexedAlert
+		for i := 0; i < f.n; i++ {
+			all = append(all, <-f.res...)
+		}
+		// 같은 탐지기 안에서는 요약이 이벤트 알림보다 먼저 (Engine.Process와 동일),
+		// 여러 샤드에서 온 요약은 시간·키 순으로 고정
+		sort.Slice(all, func(i, j int) bool {
+			a, b := all[i], all[j]
+			if a.detector != b.detector {
+				return a.detector < b.detector
+			}
+			if a.alert.Summary != b.alert.Summary {
+				return a.alert.Summary
+			}
+			if !a.alert.LastSeen.Equal(b.alert.LastSeen) {
+				return a.alert.LastSeen.Before(b.alert.LastSeen)
+			}
+			return a.alert.Key < b.alert.Key
+		})
+
+		var alerts []detector.Alert
+		for _, ia := range all {
+			alerts = append(alerts, ia.alert)
+		}
+		if f.final && len(alerts) == 0 {
+			continue
+		}
+		e.sink(f.ev, alerts)
+	}
+}
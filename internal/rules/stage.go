@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/normalizer"
+)
+
+// Sink receives every submitted event with the alerts it raised (nil for
+// none, or when the event was dropped as late), in submission order. On
+// Close it is called once more with the zero Event and the alerts flushed
+// at end of input, if any.
+type Sink func(ev normalizer.Event, alerts []detector.Alert)
+
+// Stage is the streaming form of an engine: events go in with Submit and
+// come out through the Sink. Submit and Close must be called from one
+// goroutine.
+type Stage interface {
+	Submit(ev normalizer.Event)
+	// Close flushes the alerts still held back and waits until everything
+	// has reached the Sink.
+	Close()
+	// Watermark is as on Engine; read it after Close or from the
+	// submitting goroutine.
+	Watermark() time.Time
+	// Late and Stats may be read from any goroutine.
+	Late() uint64
+	Stats() []DetectorStats
+}
+
+// Serial runs e on the submitting goroutine.
+func Serial(e *Engine, sink Sink) Stage {
+	return &serial{Engine: e, sink: sink}
+}
+
+type serial struct {
+	*Engine
+	sink Sink
+}
+
+func (s *serial) Submit(ev normalizer.Event) { s.sink(ev, s.Process(ev)) }
+
+func (s *serial) Close() {
+	if alerts := s.Flush(); len(alerts) > 0 {
+		s.sink(normalizer.Event{}, alerts)
+	}
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+)
+
+// Detail is the text of the detail pane: the alert's fields, its Korean
+// message and every contributing event with its raw line.
+func Detail(a detector.Alert) string {
+	r := format.ToRecord(a)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🚨 제목: %s\n", r.Title)
+	fmt.Fprintf(&b, "등급: %s\n", r.Severity)
+	fmt.Fprintf(&b, "시간: %s\n", r.TS.Format(time.RFC3339))
+	if r.IP != "" {
+		fmt.Fprintf(&b, "IP: %s\n", r.IP)
+	}
+	if r.Service != "" {
+		fmt.Fprintf(&b, "서비스: %s\n", r.Service)
+	}
+	if r.RuleID != "" {
+		fmt.Fprintf(&b, "RuleID: %s\n", r.RuleID)
+	}
+	b.WriteString("\n원문 메시지\n")
+	b.WriteString(format.Message(a) + "\n")
+
+	if len(a.Events) > 0 {
+		fmt.Fprintf(&b, "\n관련 이벤트 (%d건)\n", len(a.Events))
+		for _, ev := range a.Events {
+			fmt.Fprintf(&b, "  %s  %s %s %s", ev.TS.UTC().Format(time.RFC3339), ev.Service, ev.Action, ev.Status)
+			if ev.User != "" {
+				fmt.Fprintf(&b, "  user=%s", ev.User)
+			}
+			if ev.IP != "" {
+				fmt.Fprintf(&b, "  ip=%s", ev.IP)
+			}
+			if ev.Path != "" {
+				fmt.Fprintf(&b, "  path=%s", ev.Path)
+			}
+			b.WriteString("\n")
+			if ev.RawLine != "" {
+				fmt.Fprintf(&b, "    %s\n", ev.RawLine)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+)
+
+// Filter selects the alerts shown in the list. It is parsed from the
+// filter bar, a space-separated query:
+//
+//	sev:high       severity at least high (low, medium, high, critical or 낮음/중간/높음/치명)
+//	rule:SSH       rule ID contains SSH
+//	svc:auth       an event of the alert came from service auth
+//	ip:10.0.0.0/8  the key or an event IP is in the prefix (or equals the IP)
+//	anything else  free text, matched against rule, title, key and related entities
+//
+// All terms must match; an empty query matches everything.
+type Filter struct {
+	MinSeverity detector.Severity // 0 = any
+	Rule        string
+	Service     string
+	Prefix      netip.Prefix // invalid = any
+	Text        []string     // lower-cased
+
+	query string
+}
+
+// ParseFilter parses a filter bar query.
+func ParseFilter(query string) (Filter, error) {
+	f := Filter{query: strings.TrimSpace(query)}
+	for _, tok := range strings.Fields(query) {
+		field, val, ok := strings.Cut(tok, ":")
+		if !ok || val == "" {
+			f.Text = append(f.Text, strings.ToLower(tok))
+			continue
+		}
+		switch strings.ToLower(field) {
+		case "sev", "severity":
+			sev, err := parseSeverity(val)
+			if err != nil {
+				return Filter{}, err
+			}
+			f.MinSeverity = sev
+		case "rule":
+			f.Rule = strings.ToUpper(val)
+		case "svc", "service":
+			f.Service = strings.ToLower(val)
+		case "ip":
+			p, err := parsePrefix(val)
+			if err != nil {
+				return Filter{}, err
+			}
+			f.Prefix = p
+		default:
+			// "http://..." 같은 자유 텍스트
+			f.Text = append(f.Text, strings.ToLower(tok))
+		}
+	}
+	return f, nil
+}
+
+func parseSeverity(s string) (detector.Severity, error) {
+	for _, sev := range []detector.Severity{
+		detector.SeverityLow, detector.SeverityMedium, detector.SeverityHigh, detector.SeverityCritical,
+	} {
+		if s == format.SeverityKR(sev) {
+			return sev, nil
+		}
+	}
+	return detector.ParseSeverity(strings.ToLower(s))
+}
+
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("ip: bad CIDR %q", s)
+		}
+		return p.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("ip: bad address %q", s)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// String is the query the filter was parsed from.
+func (f Filter) String() string { return f.query }
+
+// Empty reports whether the filter matches everything.
+func (f Filter) Empty() bool { return f.query == "" }
+
+func (f Filter) Match(a detector.Alert) bool {
+	if a.Severity < f.MinSeverity {
+		return false
+	}
+	if f.Rule != "" && !strings.Contains(a.RuleID, f.Rule) {
+		return false
+	}
+	if f.Service != "" && !anyEvent(a, func(svc, _ string) bool { return strings.ToLower(svc) == f.Service }) {
+		return false
+	}
+	if f.Prefix.IsValid() && !f.matchIP(a) {
+		return false
+	}
+	if len(f.Text) > 0 {
+		hay := haystack(a)
+		for _, t := range f.Text {
+			if !strings.Contains(hay, t) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (f Filter) matchIP(a detector.Alert) bool {
+	in := func(s string) bool {
+		addr, err := netip.ParseAddr(s)
+		return err == nil && f.Prefix.Contains(addr.Unmap())
+	}
+	return in(a.Key) || anyEvent(a, func(_, ip string) bool { return in(ip) })
+}
+
+func anyEvent(a detector.Alert, ok func(service, ip string) bool) bool {
+	for _, ev := range a.Events {
+		if ok(ev.Service, ev.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// haystack is the lower-cased text free-text terms are matched against.
+func haystack(a detector.Alert) string {
+	parts := []string{a.RuleID, format.Title(a), a.Key}
+	for _, vals := range a.Related {
+		parts = append(parts, vals...)
+	}
+	return strings.ToLower(strings.Join(parts, " "))
+}
@@ -0,0 +1,446 @@
+// Package tui holds the pieces shared by the terminal dashboards
+// (cmd/logshield-tui, cmd/loggen): the alert list with its filter bar,
+// sort modes and group-by-IP view.
+package tui
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SortMode orders the list, most interesting first.
+type SortMode int
+
+const (
+	SortTime     SortMode = iota // newest last-seen first
+	SortSeverity                 // highest severity, then newest
+	SortCount                    // highest count, then newest
+	numSortModes
+)
+
+func (s SortMode) String() string {
+	switch s {
+	case SortSeverity:
+		return "등급"
+	case SortCount:
+		return "횟수"
+	}
+	return "시간"
+}
+
+// Group is one row of the group-by-IP view.
+type Group struct {
+	IP       string
+	Alerts   int
+	Count    int // sum of alert counts
+	Severity detector.Severity
+	LastSeen time.Time
+	Rules    []string
+}
+
+// Row is one line of the list: an alert, or a group in the IP view.
+type Row struct {
+	Alert *detector.Alert
+	Group *Group
+}
+
+type entry struct {
+	seq   uint64 // arrival order
+	alert detector.Alert
+}
+
+// AlertList keeps the last Max alerts and the rows currently shown.
+// Rows are rebuilt only when alerts or the view settings change, so
+// rendering stays cheap while events stream in.
+type AlertList struct {
+	max     int
+	entries []entry
+	seq     uint64
+
+	filter Filter
+	sort   SortMode
+	byIP   bool
+
+	rows     []Row
+	rowSeq   []uint64 // entry seq per row (alert rows)
+	matched  int      // alerts passing the filter
+	selected int
+	selSeq   uint64 // seq or group of the selected row, kept across rebuilds
+	selIP    string
+
+	// 화면에 보이는 구간: rows[offset : offset+height]
+	width, height int // 0 = unknown: show every row
+	offset        int
+	styles        Styles
+
+	// 필터 입력 중인 쿼리 (editing이면 키 입력이 여기로 감)
+	editing bool
+	input   []rune
+	err     error
+}
+
+// NewAlertList keeps at most max alerts (oldest dropped first).
+func NewAlertList(max int) AlertList {
+	return AlertList{max: max}
+}
+
+// Add appends alerts in arrival order. A cursor on the first row stays
+// there (following new alerts); elsewhere it stays on its alert.
+func (l *AlertList) Add(as ...detector.Alert) {
+	follow := l.selected == 0
+	for _, a := range as {
+		l.seq++
+		l.entries = append(l.entries, entry{seq: l.seq, alert: a})
+	}
+	if over := len(l.entries) - l.max; l.max > 0 && over > 0 {
+		l.entries = append(l.entries[:0], l.entries[over:]...)
+	}
+	l.rebuild()
+	if follow {
+		l.Select(0)
+	}
+}
+
+func (l *AlertList) Clear() {
+	l.entries = nil
+	l.selected, l.selSeq, l.selIP = 0, 0, ""
+	l.rebuild()
+}
+
+// Len is the number of alerts kept; Rows may show fewer (filter) or
+// group them.
+func (l *AlertList) Len() int { return len(l.entries) }
+
+func (l *AlertList) Rows() []Row   { return l.rows }
+func (l *AlertList) Selected() int { return l.selected }
+
+// SelectedAlert is the alert under the cursor (false on a group row).
+func (l *AlertList) SelectedAlert() (detector.Alert, bool) {
+	if l.selected >= len(l.rows) || l.rows[l.selected].Alert == nil {
+		return detector.Alert{}, false
+	}
+	return *l.rows[l.selected].Alert, true
+}
+
+// SelectedSeq identifies the selected alert across sorts and filters
+// (0 on a group row or an empty list).
+func (l *AlertList) SelectedSeq() uint64 { return l.selSeq }
+
+// Alerts returns the alerts that pass the filter, in list order.
+func (l *AlertList) Alerts() []detector.Alert {
+	var out []detector.Alert
+	for _, e := range l.sorted() {
+		out = append(out, e.alert)
+	}
+	return out
+}
+
+func (l *AlertList) Filter() Filter { return l.filter }
+
+// Editing reports whether the filter bar has the keyboard.
+func (l *AlertList) Editing() bool { return l.editing }
+
+// Select moves the cursor to row i (clamped), scrolling it into view.
+func (l *AlertList) Select(i int) {
+	if len(l.rows) == 0 {
+		l.selected = 0
+		l.scroll()
+		return
+	}
+	l.selected = max(0, min(i, len(l.rows)-1))
+	l.remember()
+	l.scroll()
+}
+
+func (l *AlertList) SetStyles(s Styles) { l.styles = s }
+
+// SetSize sets the space for the rows (the position line not included).
+func (l *AlertList) SetSize(width, height int) {
+	l.width, l.height = width, max(height, 1)
+	l.scroll()
+}
+
+// scroll moves the window just enough to show the cursor.
+func (l *AlertList) scroll() {
+	n := l.visible()
+	if l.selected < l.offset {
+		l.offset = l.selected
+	}
+	if l.selected >= l.offset+n {
+		l.offset = l.selected - n + 1
+	}
+	l.offset = max(0, min(l.offset, len(l.rows)-n))
+}
+
+func (l *AlertList) visible() int {
+	if l.height == 0 {
+		return len(l.rows)
+	}
+	return min(l.height, len(l.rows))
+}
+
+// HandleKey applies the list's own keys and reports whether k was used:
+//
+//	/      edit the filter (enter applies, esc cancels)
+//	x      clear the filter
+//	o      next sort mode
+//	i      toggle the group-by-IP view
+//	↑/k ↓/j g G   move
+//	pgup pgdown   move a page
+//	enter  on a group row: show that IP's alerts
+func (l *AlertList) HandleKey(k tea.KeyMsg) bool {
+	if l.editing {
+		l.editKey(k)
+		return true
+	}
+	switch k.String() {
+	case "/":
+		l.editing = true
+		l.input = []rune(l.filter.String())
+		l.err = nil
+	case "x":
+		l.filter = Filter{}
+		l.rebuild()
+	case "o":
+		l.sort = (l.sort + 1) % numSortModes
+		l.rebuild()
+	case "i":
+		l.byIP = !l.byIP
+		l.rebuild()
+	case "up", "k":
+		l.Select(l.selected - 1)
+	case "down", "j":
+		l.Select(l.selected + 1)
+	case "pgup":
+		l.Select(l.selected - max(l.visible()-1, 1))
+	case "pgdown":
+		l.Select(l.selected + max(l.visible()-1, 1))
+	case "g", "home":
+		l.Select(0)
+	case "G", "end":
+		l.Select(len(l.rows) - 1)
+	case "enter":
+		if l.selected >= len(l.rows) || l.rows[l.selected].Group == nil {
+			return false
+		}
+		// 그룹 → 해당 IP의 경고 목록으로 드릴다운
+		f, err := ParseFilter(strings.TrimSpace(l.filter.String() + " ip:" + l.rows[l.selected].Group.IP))
+		if err != nil {
+			l.err = err
+			return true
+		}
+		l.filter, l.byIP = f, false
+		l.rebuild()
+		l.Select(0)
+	default:
+		return false
+	}
+	return true
+}
+
+func (l *AlertList) editKey(k tea.KeyMsg) {
+	switch k.Type {
+	case tea.KeyEnter:
+		f, err := ParseFilter(string(l.input))
+		if err != nil {
+			l.err = err // 입력은 유지하고 에러만 표시
+			return
+		}
+		l.filter, l.editing, l.err = f, false, nil
+		l.rebuild()
+	case tea.KeyEsc:
+		l.editing, l.err = false, nil
+	case tea.KeyBackspace:
+		if n := len(l.input); n > 0 {
+			l.input = l.input[:n-1]
+		}
+	case tea.KeyCtrlU:
+		l.input = nil
+	case tea.KeySpace:
+		l.input = append(l.input, ' ')
+	case tea.KeyRunes:
+		l.input = append(l.input, k.Runes...)
+	}
+}
+
+// Bar is the filter/sort status line shown above the list.
+func (l *AlertList) Bar() string {
+	var b strings.Builder
+	if l.editing {
+		fmt.Fprintf(&b, "필터> %s█  (enter 적용, esc 취소; sev: rule: svc: ip: 텍스트)", string(l.input))
+	} else {
+		q := l.filter.String()
+		if q == "" {
+			q = "(없음, /로 입력)"
+		}
+		fmt.Fprintf(&b, "필터: %s | 정렬: %s | 보기: %s | %d/%d건",
+			q, l.sort, l.viewName(), l.matched, len(l.entries))
+	}
+	if l.err != nil {
+		fmt.Fprintf(&b, "\n❌ 필터 오류: %v", l.err)
+	}
+	return b.String()
+}
+
+func (l *AlertList) viewName() string {
+	if l.byIP {
+		return "IP별 그룹"
+	}
+	return "경고"
+}
+
+// View renders the visible rows ("> " marks the cursor) with a
+// scrollbar, then a position line.
+func (l *AlertList) View() string {
+	if len(l.rows) == 0 {
+		if len(l.entries) > 0 {
+			return "(필터에 맞는 경고 없음 — x로 필터 해제)\n"
+		}
+		return ""
+	}
+	n := l.visible()
+	lines := make([]string, 0, n)
+	for i := l.offset; i < l.offset+n; i++ {
+		if i == l.selected {
+			lines = append(lines, l.styles.Selected.Render("> "+l.styles.Row(l.rows[i])))
+			continue
+		}
+		lines = append(lines, "  "+l.styles.Row(l.rows[i]))
+	}
+	var b strings.Builder
+	l.styles.writeLines(&b, lines, l.width, l.styles.scrollbar(len(l.rows), l.offset, n))
+	b.WriteString(l.styles.position(l.offset, n, len(l.rows), "행"))
+	return b.String()
+}
+
+// Row is the one-line form of a list row.
+func (s Styles) Row(r Row) string {
+	if g := r.Group; g != nil {
+		return fmt.Sprintf("%s %-15s  경고 %d건  누적 %d회  (%s)  %s",
+			s.Badge(g.Severity), g.IP, g.Alerts, g.Count,
+			g.LastSeen.Format("15:04:05"), s.Dim.Render(strings.Join(g.Rules, ",")))
+	}
+	a := r.Alert
+	title := format.Title(*a)
+	if a.Summary {
+		title = "[종료 요약] " + title
+	}
+	return fmt.Sprintf("%s %s  %s  ×%d  %s",
+		s.Badge(a.Severity), title, a.Key, a.Count,
+		s.Dim.Render("("+a.LastSeen.Format("15:04:05")+")"))
+}
+
+// sorted returns the entries passing the filter in the current order.
+func (l *AlertList) sorted() []entry {
+	var es []entry
+	for i := len(l.entries) - 1; i >= 0; i-- { // 최신 도착 먼저 (동률일 때)
+		if l.filter.Match(l.entries[i].alert) {
+			es = append(es, l.entries[i])
+		}
+	}
+	sort.SliceStable(es, func(i, j int) bool {
+		a, b := es[i].alert, es[j].alert
+		return less(l.sort, a.Severity, b.Severity, a.Count, b.Count, a.LastSeen, b.LastSeen)
+	})
+	return es
+}
+
+func less(mode SortMode, sa, sb detector.Severity, ca, cb int, ta, tb time.Time) bool {
+	switch mode {
+	case SortSeverity:
+		if sa != sb {
+			return sa > sb
+		}
+	case SortCount:
+		if ca != cb {
+			return ca > cb
+		}
+	}
+	return ta.After(tb)
+}
+
+func (l *AlertList) rebuild() {
+	es := l.sorted()
+	l.matched = len(es)
+	l.rows, l.rowSeq = l.rows[:0], l.rowSeq[:0]
+
+	if !l.byIP {
+		for i := range es {
+			l.rows = append(l.rows, Row{Alert: &es[i].alert})
+			l.rowSeq = append(l.rowSeq, es[i].seq)
+		}
+	} else {
+		groups := make(map[string]*Group)
+		var order []*Group
+		for _, e := range es {
+			ip := alertIP(e.alert)
+			g, ok := groups[ip]
+			if !ok {
+				g = &Group{IP: ip}
+				groups[ip] = g
+				order = append(order, g)
+			}
+			g.Alerts++
+			g.Count += e.alert.Count
+			g.Severity = max(g.Severity, e.alert.Severity)
+			if e.alert.LastSeen.After(g.LastSeen) {
+				g.LastSeen = e.alert.LastSeen
+			}
+			if !slices.Contains(g.Rules, e.alert.RuleID) {
+				g.Rules = append(g.Rules, e.alert.RuleID)
+			}
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			a, b := order[i], order[j]
+			return less(l.sort, a.Severity, b.Severity, a.Count, b.Count, a.LastSeen, b.LastSeen)
+		})
+		for _, g := range order {
+			l.rows = append(l.rows, Row{Group: g})
+			l.rowSeq = append(l.rowSeq, 0)
+		}
+	}
+
+	// 목록이 바뀌어도 같은 항목에 커서 유지
+	l.selected = 0
+	for i, r := range l.rows {
+		if (r.Group != nil && r.Group.IP == l.selIP) || (r.Alert != nil && l.rowSeq[i] == l.selSeq) {
+			l.selected = i
+			break
+		}
+	}
+	l.remember()
+	l.scroll()
+}
+
+func (l *AlertList) remember() {
+	l.selSeq, l.selIP = 0, ""
+	if l.selected >= len(l.rows) {
+		return
+	}
+	if g := l.rows[l.selected].Group; g != nil {
+		l.selIP = g.IP
+	} else {
+		l.selSeq = l.rowSeq[l.selected]
+	}
+}
+
+// alertIP is the IP an alert is grouped under: the key when it is an IP
+// (most rules), else the IP of its last event.
+func alertIP(a detector.Alert) string {
+	if _, err := netip.ParseAddr(a.Key); err == nil {
+		return a.Key
+	}
+	if n := len(a.Events); n > 0 && a.Events[n-1].IP != "" {
+		return a.Events[n-1].IP
+	}
+	return "(IP 없음)"
+}
@@ -0,0 +1,334 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+	"go-logshield/internal/normalizer"
+	"go-logshield/internal/rules"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Options configure a dashboard.
+type Options struct {
+	Title     string
+	MaxAlerts int    // alerts kept in the list (default 2000)
+	Status    string // initial status line
+	Styles    Styles
+}
+
+// --- 소스(파이프라인, 데모) → 대시보드 메시지: p.Send로 보냄 ---
+
+// AlertMsg adds an alert.
+type AlertMsg struct{ Alert detector.Alert }
+
+// EventMsg counts one parsed event.
+type EventMsg struct{ Event normalizer.Event }
+
+// ParseErrMsg counts a line that could not be read or parsed.
+type ParseErrMsg struct {
+	Source string
+	Err    error
+}
+
+// StatusMsg sets the status line.
+type StatusMsg string
+
+// DoneMsg tells that every input has been read to the end.
+type DoneMsg struct{}
+
+// ErrMsg shows an error in the status line.
+type ErrMsg struct{ Err error }
+
+// StageMsg hands over the detection stage's counters once the stage is
+// built; the dashboard polls them every second. A later StageMsg (a new
+// stage per file) keeps the late count of the stages before it.
+type StageMsg struct {
+	Stats func() []rules.DetectorStats
+	Late  func() uint64 // events dropped as too late
+}
+
+type savedMsg struct{ path string }
+
+// statsTickMsg rolls the stats tab's per-second window.
+type statsTickMsg time.Time
+
+func statsTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return statsTickMsg(t) })
+}
+
+type focus int
+
+const (
+	focusList focus = iota
+	focusDetail
+)
+
+type tab int
+
+const (
+	tabAlerts tab = iota
+	tabStats
+)
+
+// Model is the alert dashboard shared by cmd/logshield-tui and
+// cmd/loggen: a header stats bar, the alert list and the detail of the
+// selected alert side by side (stacked on narrow terminals), and a help
+// bar.
+type Model struct {
+	opts Options
+	st   Styles
+
+	paused   bool
+	showHelp bool // 전체 단축키 표시 (기본은 한 줄 도움말)
+	tab      tab
+	focus    focus
+
+	// 경고 목록 (필터/정렬/IP 그룹은 목록이 처리)
+	list AlertList
+	// 선택된 경고의 상세 (긴 메시지/이벤트 목록은 스크롤)
+	detail    Pager
+	detailSeq uint64 // detail이 보여주는 경고 (목록 seq)
+
+	// 일시정지 중 들어온 경고 (재개 시 목록에 추가, 최대 MaxAlerts건)
+	pending []detector.Alert
+
+	statusLine string // 저장 완료/에러 같은 상태 메시지
+
+	// 통계
+	totalEvents int
+	totalAlerts int
+	parseErrors int
+	lateEvents  uint64 // 이벤트 중 allowed_lateness를 넘겨 탐지 단계가 버린 수
+
+	// 통계 탭 (t): 초당 이벤트, 상위 IP, 규칙별 경고, 탐지기 상태
+	stats         Stats
+	statsView     Pager
+	detectorStats func() []rules.DetectorStats
+	late          func() uint64
+	lateBefore    uint64 // 이전 탐지 단계들의 지연 폐기 수
+
+	// 터미널 크기 (WindowSizeMsg 전에는 0 → 전부 출력)
+	width, height int
+}
+
+func New(opts Options) Model {
+	if opts.MaxAlerts <= 0 {
+		opts.MaxAlerts = 2000
+	}
+	m := Model{
+		opts:       opts,
+		st:         opts.Styles,
+		list:       NewAlertList(opts.MaxAlerts),
+		stats:      NewStats(),
+		statusLine: opts.Status,
+	}
+	m.list.SetStyles(opts.Styles)
+	m.detail.SetStyles(opts.Styles)
+	m.statsView.SetStyles(opts.Styles)
+	return m
+}
+
+func (m Model) Init() tea.Cmd { return statsTick() }
+
+func saveReportCmd(alerts []detector.Alert) tea.Cmd {
+	// alerts를 report.json 레코드로 복사해서 클로저에서 안전하게 사용
+	snapshot := make([]format.Record, 0, len(alerts))
+	for _, a := range alerts {
+		snapshot = append(snapshot, format.ToRecord(a))
+	}
+
+	return func() tea.Msg {
+		b, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return ErrMsg{Err: err}
+		}
+		path := "report.json"
+		if err := os.WriteFile(path, b, 0644); err != nil {
+			return ErrMsg{Err: err}
+		}
+		return savedMsg{path: path}
+	}
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+	m.layout()
+	m.syncDetail()
+	switch msg.(type) {
+	case tea.KeyMsg, tea.WindowSizeMsg, statsTickMsg:
+		// 이벤트마다 다시 그리지 않고 1초마다(또는 키 입력 시)만 갱신
+		m.refreshStats()
+	}
+	return m, cmd
+}
+
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
+	switch x := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.width, m.height = x.Width, x.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		k := x.String()
+		if k == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+		// --- 목록 단축키(필터 입력, 정렬, IP 그룹, 이동) / 상세·통계 스크롤 ---
+		switch {
+		case m.tab == tabStats:
+			if m.statsView.HandleKey(x) {
+				return m, nil
+			}
+		case m.focus == focusList:
+			if m.list.HandleKey(x) {
+				return m, nil
+			}
+		default:
+			if m.detail.HandleKey(x) {
+				return m, nil
+			}
+		}
+
+		// --- 글로벌 단축키 ---
+		switch k {
+		case "q":
+			return m, tea.Quit
+		case "h", "?":
+			m.showHelp = !m.showHelp
+		case "t":
+			// 경고 목록 ↔ 통계 탭
+			if m.tab == tabAlerts {
+				m.tab = tabStats
+			} else {
+				m.tab = tabAlerts
+			}
+		case "p":
+			m.paused = !m.paused
+			if m.paused {
+				m.statusLine = "⏸ 일시정지됨 (p로 재개) — 새 경고는 대기열에 보관"
+			} else {
+				m.statusLine = fmt.Sprintf("▶ 분석 재개됨 (대기 경고 %d건 반영)", len(m.pending))
+				m.list.Add(m.pending...)
+				m.pending = nil
+			}
+		case "c":
+			m.list.Clear()
+			m.pending = nil
+			m.focus = focusList
+			m.statusLine = "🧹 경고 목록 초기화"
+		case "s":
+			// 현재 필터에 맞는 경고를 목록 순서대로 report.json으로 저장
+			alerts := m.list.Alerts()
+			if len(alerts) == 0 {
+				m.statusLine = "저장할 경고가 없습니다."
+				return m, nil
+			}
+			m.statusLine = "💾 report.json 저장 중..."
+			return m, saveReportCmd(alerts)
+		case "enter", "tab":
+			// 목록 ↔ 상세 포커스 전환 (상세는 스크롤 가능)
+			if _, ok := m.list.SelectedAlert(); !ok || m.tab == tabStats {
+				return m, nil
+			}
+			if m.focus == focusList {
+				m.focus = focusDetail
+			} else {
+				m.focus = focusList
+			}
+		case "esc":
+			m.tab, m.focus = tabAlerts, focusList
+		}
+		return m, nil
+
+	case AlertMsg:
+		m.totalAlerts++
+		m.stats.Alert(x.Alert)
+		if m.paused {
+			// 목록에 MaxAlerts건만 남으므로 대기열도 그만큼만 (오래된 것부터 버림)
+			m.pending = append(m.pending, x.Alert)
+			if over := len(m.pending) - m.opts.MaxAlerts; over > 0 {
+				m.pending = append(m.pending[:0], m.pending[over:]...)
+			}
+			return m, nil
+		}
+		m.list.Add(x.Alert)
+		m.statusLine = fmt.Sprintf("🚨 새 경고: %s", format.Title(x.Alert))
+
+	case EventMsg:
+		m.totalEvents++
+		m.stats.Event(x.Event, time.Now())
+
+	case ParseErrMsg:
+		// 파싱 에러는 카운트 + 상태라인만 살짝(도배 방지)
+		m.parseErrors++
+		m.stats.ParseError(time.Now())
+		m.statusLine = fmt.Sprintf("❌ parse error (%s): %v", x.Source, x.Err)
+
+	case StatusMsg:
+		m.statusLine = string(x)
+
+	case DoneMsg:
+		m.statusLine = "✅ 입력을 끝까지 분석했습니다 (q 종료)"
+
+	case StageMsg:
+		if m.late != nil {
+			m.lateBefore += m.late()
+		}
+		m.detectorStats, m.late = x.Stats, x.Late
+
+	case statsTickMsg:
+		m.stats.Advance(time.Time(x))
+		if m.detectorStats != nil {
+			m.stats.SetDetectors(m.detectorStats())
+		}
+		if m.late != nil {
+			m.lateEvents = m.lateBefore + m.late()
+			m.stats.SetLate(m.lateEvents)
+		}
+		return m, statsTick()
+
+	case savedMsg:
+		m.statusLine = fmt.Sprintf("✅ 저장 완료: %s", x.path)
+
+	case ErrMsg:
+		m.statusLine = fmt.Sprintf("❌ 오류: %v", x.Err)
+	}
+	return m, nil
+}
+
+// syncDetail loads the selected alert into the detail pane when the
+// selection changed.
+func (m *Model) syncDetail() {
+	a, ok := m.list.SelectedAlert()
+	if !ok {
+		m.detailSeq = 0
+		if m.focus == focusDetail {
+			m.focus = focusList
+		}
+		return
+	}
+	if seq := m.list.SelectedSeq(); seq != m.detailSeq {
+		m.detailSeq = seq
+		m.detail.SetContent(Detail(a))
+	}
+}
+
+// refreshStats redraws the stats tab when it is shown.
+func (m *Model) refreshStats() {
+	if m.tab != tabStats {
+		return
+	}
+	w := 0
+	if m.width > 0 {
+		w = m.width - 3 // 테두리 + 스크롤바
+	}
+	m.statsView.Refresh(m.stats.Render(m.st, w))
+}
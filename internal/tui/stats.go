@@ -0,0 +1,304 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/normalizer"
+	"go-logshield/internal/rules"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// statsWindow is how many seconds the rate sparklines cover.
+const statsWindow = 60
+
+// topN is how many IPs the stats tab lists per ranking.
+const topN = 10
+
+// maxIPs bounds the per-IP counters; past it the quieter half is dropped
+// so a scan over many addresses cannot grow the dashboard without limit.
+const maxIPs = 10000
+
+// series counts something per second over the last statsWindow seconds.
+type series [statsWindow]uint64
+
+func (s *series) add(sec int64)   { s[sec%statsWindow]++ }
+func (s *series) clear(sec int64) { s[sec%statsWindow] = 0 }
+
+// last returns the n seconds up to sec, oldest first.
+func (s *series) last(sec int64, n int) []uint64 {
+	out := make([]uint64, n)
+	for i := range out {
+		out[i] = s[(sec-int64(n-1-i))%statsWindow]
+	}
+	return out
+}
+
+func (s *series) sum() uint64 {
+	var n uint64
+	for _, v := range s {
+		n += v
+	}
+	return n
+}
+
+type ruleCount struct {
+	alerts     int
+	summaries  int
+	suppressed int
+}
+
+// Stats is the live statistics tab: event and parse error rates over the
+// last minute, the IPs with the most failures and hits, alerts per rule
+// and the keys each detector tracks. Rates use arrival (wall) time so an
+// attack shows while it is building, before any threshold fires.
+type Stats struct {
+	sec      int64 // second of the newest bucket
+	events   series
+	errors   series
+	services map[string]*series
+
+	hits  map[string]uint64 // 이벤트 수 (IP별)
+	fails map[string]uint64 // status=FAIL 이벤트 수 (IP별)
+
+	rules     map[string]*ruleCount
+	ruleOrder []string // 처음 본 순서
+
+	detectors []rules.DetectorStats
+	late      uint64
+}
+
+func NewStats() Stats {
+	return Stats{
+		services: make(map[string]*series),
+		hits:     make(map[string]uint64),
+		fails:    make(map[string]uint64),
+		rules:    make(map[string]*ruleCount),
+	}
+}
+
+// Advance moves the window to now, zeroing the seconds that passed
+// without events.
+func (s *Stats) Advance(now time.Time) {
+	sec := now.Unix()
+	if sec <= s.sec {
+		return
+	}
+	for t := max(s.sec+1, sec-statsWindow+1); t <= sec; t++ {
+		s.events.clear(t)
+		s.errors.clear(t)
+		for _, sv := range s.services {
+			sv.clear(t)
+		}
+	}
+	s.sec = sec
+}
+
+// Event counts one parsed event received at now.
+func (s *Stats) Event(ev normalizer.Event, now time.Time) {
+	s.Advance(now)
+	s.events.add(s.sec)
+
+	svc := ev.Service
+	if svc == "" {
+		svc = "(unknown)"
+	}
+	sv, ok := s.services[svc]
+	if !ok {
+		sv = new(series)
+		s.services[svc] = sv
+	}
+	sv.add(s.sec)
+
+	if ev.IP == "" {
+		return
+	}
+	s.hits[ev.IP]++
+	if ev.Status == "FAIL" {
+		s.fails[ev.IP]++
+	}
+	if len(s.hits) > maxIPs {
+		s.prune()
+	}
+}
+
+// prune keeps the maxIPs/2 IPs with the most hits.
+func (s *Stats) prune() {
+	keep := top(s.hits, maxIPs/2)
+	kept := make(map[string]bool, len(keep))
+	for _, kv := range keep {
+		kept[kv.key] = true
+	}
+	for ip := range s.hits {
+		if !kept[ip] {
+			delete(s.hits, ip)
+			delete(s.fails, ip)
+		}
+	}
+}
+
+// ParseError counts one unparsable line received at now.
+func (s *Stats) ParseError(now time.Time) {
+	s.Advance(now)
+	s.errors.add(s.sec)
+}
+
+// Alert counts an alert (or an incident summary) under its rule.
+func (s *Stats) Alert(a detector.Alert) {
+	rc, ok := s.rules[a.RuleID]
+	if !ok {
+		rc = &ruleCount{}
+		s.rules[a.RuleID] = rc
+		s.ruleOrder = append(s.ruleOrder, a.RuleID)
+	}
+	if a.Summary {
+		rc.summaries++
+		rc.suppressed += a.Suppressed
+	} else {
+		rc.alerts++
+	}
+}
+
+// SetDetectors replaces the detector state snapshot.
+func (s *Stats) SetDetectors(d []rules.DetectorStats) { s.detectors = d }
+
+// SetLate sets the number of events the stage dropped as too late.
+func (s *Stats) SetLate(n uint64) { s.late = n }
+
+type keyCount struct {
+	key string
+	n   uint64
+}
+
+// top returns the n largest counts, ties by key.
+func top(m map[string]uint64, n int) []keyCount {
+	out := make([]keyCount, 0, len(m))
+	for k, v := range m {
+		if v > 0 {
+			out = append(out, keyCount{k, v})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].n != out[j].n {
+			return out[i].n > out[j].n
+		}
+		return out[i].key < out[j].key
+	})
+	return out[:min(n, len(out))]
+}
+
+// sparkRamp are the sparkline levels; plain styles stay in ASCII.
+var (
+	sparkRamp      = []rune(" ▁▂▃▄▅▆▇█")
+	sparkRampPlain = []rune(" .:-=+*#")
+)
+
+func (st Styles) sparkline(vals []uint64) string {
+	ramp := sparkRampPlain
+	if st.Color {
+		ramp = sparkRamp
+	}
+	var peak uint64
+	for _, v := range vals {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range vals {
+		i := 0
+		if v > 0 {
+			// 0이 아니면 최소 한 칸은 보이게
+			i = max(1, int(v*uint64(len(ramp)-1)/peak))
+		}
+		b.WriteRune(ramp[i])
+	}
+	return b.String()
+}
+
+func (st Styles) bar(v, peak uint64, width int) string {
+	c := "#"
+	if st.Color {
+		c = "█"
+	}
+	if peak == 0 {
+		return ""
+	}
+	return strings.Repeat(c, max(1, int(v*uint64(width)/peak)))
+}
+
+// pad fills s with spaces to width terminal cells.
+func pad(s string, width int) string {
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", max(width-ansi.StringWidth(s), 0))
+}
+
+// Render is the text of the stats tab for a pane width cells wide
+// (0 = unknown).
+func (s *Stats) Render(st Styles, width int) string {
+	const label = 16
+	spark := statsWindow
+	if width > 0 {
+		// 라벨 + 스파크라인 + "현재 N/s 평균 N/s (합계 N)"
+		spark = max(min(statsWindow, width-label-38), 10)
+	}
+	rate := func(name string, sv *series) string {
+		now := sv[(s.sec-1+statsWindow)%statsWindow] // 마지막으로 끝난 1초
+		return fmt.Sprintf("  %s %s  현재 %d/s  평균 %.1f/s  (합계 %d)\n",
+			pad(name, label), st.sparkline(sv.last(s.sec, spark)),
+			now, float64(sv.sum())/statsWindow, sv.sum())
+	}
+
+	var b strings.Builder
+	b.WriteString(st.Title.Render(fmt.Sprintf("이벤트/초 (최근 %d초)", statsWindow)) + "\n")
+	b.WriteString(rate("전체", &s.events))
+	names := make([]string, 0, len(s.services))
+	for name := range s.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(rate(name, s.services[name]))
+	}
+	b.WriteString(rate("파싱 에러", &s.errors))
+	if total := s.events.sum() + s.errors.sum(); total > 0 {
+		fmt.Fprintf(&b, "  %s %.1f%%\n", pad("에러율", label), float64(s.errors.sum())*100/float64(total))
+	}
+	// 지연 폐기는 이벤트 수에 포함되지만 탐지기에는 전달되지 않은 이벤트
+	fmt.Fprintf(&b, "  %s %d (allowed_lateness 초과, 탐지 제외)\n", pad("지연 폐기", label), s.late)
+
+	ips := func(title string, m, other map[string]uint64, what, otherWhat string) {
+		b.WriteString("\n" + st.Title.Render(title) + "\n")
+		list := top(m, topN)
+		if len(list) == 0 {
+			b.WriteString(st.Dim.Render("  (없음)") + "\n")
+			return
+		}
+		for i, kv := range list {
+			fmt.Fprintf(&b, "  %2d. %s %s %-8d %s %-8d %s\n", i+1, pad(kv.key, 39),
+				what, kv.n, otherWhat, other[kv.key], st.bar(kv.n, list[0].n, 20))
+		}
+	}
+	ips("상위 IP — 실패 기준", s.fails, s.hits, "실패", "요청")
+	ips("상위 IP — 요청 기준", s.hits, s.fails, "요청", "실패")
+
+	b.WriteString("\n" + st.Title.Render("규칙별 경고") + "\n")
+	if len(s.ruleOrder) == 0 {
+		b.WriteString(st.Dim.Render("  (없음)") + "\n")
+	}
+	for _, id := range s.ruleOrder {
+		rc := s.rules[id]
+		fmt.Fprintf(&b, "  %s 경고 %-6d 종료 요약 %-6d 억제 %d\n", pad(id, 28), rc.alerts, rc.summaries, rc.suppressed)
+	}
+
+	b.WriteString("\n" + st.Title.Render("탐지기 상태 (추적 중인 키)") + "\n")
+	if len(s.detectors) == 0 {
+		b.WriteString(st.Dim.Render("  (정보 없음)") + "\n")
+	}
+	for _, d := range s.detectors {
+		fmt.Fprintf(&b, "  %s 키 %-8d 이벤트 %-8d 만료 %-8d 축출 %d\n", pad(d.RuleID, 28), d.Keys, d.Events, d.Idle, d.Evicted)
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"os"
+
+	"go-logshield/internal/detector"
+	"go-logshield/internal/format"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Styles is the look of the dashboard. The zero value is plain text
+// (no color, ASCII borders), used when the terminal has no color.
+type Styles struct {
+	Color bool
+
+	Header   lipgloss.Style // stats bar
+	Status   lipgloss.Style
+	Pane     lipgloss.Style // list/detail box
+	Focused  lipgloss.Style // box with the keyboard
+	Title    lipgloss.Style // pane titles
+	Selected lipgloss.Style // row under the cursor
+	Dim      lipgloss.Style
+	HelpKey  lipgloss.Style
+	HelpBar  lipgloss.Style
+
+	badges map[detector.Severity]lipgloss.Style
+}
+
+// asciiBorder keeps the panes readable on terminals without box drawing.
+var asciiBorder = lipgloss.Border{
+	Top: "-", Bottom: "-", Left: "|", Right: "|",
+	TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+}
+
+// DetectColor reports whether stdout can show colors: not when NO_COLOR
+// is set, TERM is dumb, or the terminal has no color profile.
+func DetectColor() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return lipgloss.NewRenderer(os.Stdout).ColorProfile() != termenv.Ascii
+}
+
+// NewStyles returns the colored styles, or plain ones when color is false.
+func NewStyles(color bool) Styles {
+	if !color {
+		return Styles{
+			Pane:    lipgloss.NewStyle().Border(asciiBorder),
+			Focused: lipgloss.NewStyle().Border(asciiBorder),
+		}
+	}
+
+	badge := func(fg, bg string) lipgloss.Style {
+		return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(fg)).Background(lipgloss.Color(bg))
+	}
+	return Styles{
+		Color:    true,
+		Header:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("230")).Background(lipgloss.Color("24")),
+		Status:   lipgloss.NewStyle().Foreground(lipgloss.Color("250")),
+		Pane:     lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")),
+		Focused:  lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("39")),
+		Title:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")),
+		Selected: lipgloss.NewStyle().Bold(true).Background(lipgloss.Color("237")),
+		Dim:      lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+		HelpKey:  lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")),
+		HelpBar:  lipgloss.NewStyle().Foreground(lipgloss.Color("246")),
+		badges: map[detector.Severity]lipgloss.Style{
+			detector.SeverityCritical: badge("231", "160"),
+			detector.SeverityHigh:     badge("232", "208"),
+			detector.SeverityMedium:   badge("232", "220"),
+			detector.SeverityLow:      badge("232", "117"),
+		},
+	}
+}
+
+// Badge is the severity label: a colored block, or "[높음]" without color.
+func (s Styles) Badge(sev detector.Severity) string {
+	kr := format.SeverityKR(sev)
+	if !s.Color {
+		return "[" + kr + "]"
+	}
+	return s.badges[sev].Render(" " + kr + " ")
+}
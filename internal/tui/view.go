@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// splitMinWidth is the narrowest terminal that gets list and detail side
+// by side; below it the focused pane takes the whole width.
+const splitMinWidth = 100
+
+// listShare is the list's part of the width in the split view (percent).
+const listShare = 55
+
+// help lists the keys: the help bar shows the first keys of each line,
+// the full help (h/?) every line.
+var help = [][][2]string{
+	{{"q", "종료"}, {"p", "일시정지"}, {"/", "필터"}, {"o", "정렬"}, {"i", "IP별 그룹"}, {"tab", "상세"}, {"t", "통계"}, {"s", "저장"}, {"?", "도움말"}},
+	{{"↑/k ↓/j", "이동"}, {"pgup/pgdn", "페이지"}, {"g/G", "맨위/맨아래"}, {"enter", "상세/그룹 펼치기"}, {"esc", "목록으로"}},
+	{{"x", "필터 해제"}, {"c", "초기화"}, {"s", "report.json 저장(필터 적용)"}, {"t", "경고 목록 ↔ 통계 탭"}},
+	{{"필터", "sev:high rule:SSH svc:auth ip:10.0.0.0/8 텍스트"}},
+}
+
+func (m Model) split() bool { return m.width >= splitMinWidth }
+
+// frame is the text around the panes, rendered once for both layout and
+// View so the heights always agree.
+type frame struct {
+	header, status, bar, help string
+}
+
+func (m Model) frame() frame {
+	state := "● RUNNING"
+	if m.paused {
+		state = fmt.Sprintf("⏸ PAUSED (대기 %d)", len(m.pending))
+	}
+	sep := " │ "
+	if !m.st.Color {
+		sep = " | "
+	}
+	tabs := "[경고] 통계"
+	if m.tab == tabStats {
+		tabs = "경고 [통계]"
+	}
+	events := fmt.Sprintf("이벤트 %d", m.totalEvents)
+	if m.lateEvents > 0 {
+		events += fmt.Sprintf(" (지연 폐기 %d)", m.lateEvents)
+	}
+	header := strings.Join([]string{
+		m.opts.Title, tabs, state, events,
+		fmt.Sprintf("파싱 에러 %d", m.parseErrors),
+		fmt.Sprintf("경고 %d", m.totalAlerts),
+	}, sep)
+	hs := m.st.Header
+	if m.width > 0 {
+		hs = hs.Width(m.width)
+	}
+
+	var lines []string
+	for i, keys := range help {
+		if !m.showHelp && i > 0 {
+			break
+		}
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, m.st.HelpKey.Render(k[0])+" "+k[1])
+		}
+		lines = append(lines, m.st.HelpBar.Render(m.fit(strings.Join(parts, "  "))))
+	}
+
+	filter := m.list.Bar()
+	if m.tab == tabStats {
+		filter = fmt.Sprintf("최근 %d초, 1초마다 갱신 | 실패 = status FAIL | t: 경고 목록으로", statsWindow)
+	}
+	var bar []string
+	for _, line := range strings.Split(filter, "\n") {
+		bar = append(bar, m.fit(" "+line))
+	}
+	return frame{
+		header: hs.Render(m.fit(" " + header)),
+		status: m.st.Status.Render(m.fit(" " + m.statusLine)),
+		bar:    strings.Join(bar, "\n"),
+		help:   strings.Join(lines, "\n"),
+	}
+}
+
+// fit cuts a one-line text to the terminal width.
+func (m Model) fit(s string) string {
+	if m.width <= 0 {
+		return s
+	}
+	_, _, _, tail := m.st.glyphs()
+	return ansi.Truncate(s, m.width, tail)
+}
+
+func lineCount(s string) int { return strings.Count(s, "\n") + 1 }
+
+// body is the height left for the panes (borders included).
+func (m Model) body(f frame) int {
+	return m.height - lineCount(f.header) - lineCount(f.status) - lineCount(f.bar) - lineCount(f.help)
+}
+
+// paneWidths are the inner widths of the list and detail panes; 0 when
+// that pane is not shown.
+func (m Model) paneWidths() (list, detail int) {
+	if m.split() {
+		lw := m.width * listShare / 100
+		return lw - 2, m.width - lw - 2
+	}
+	if m.focus == focusDetail {
+		return 0, m.width - 2
+	}
+	return m.width - 2, 0
+}
+
+// layout sizes the list and the detail to the panes: a pane loses two
+// rows to its border, one to its title and one to the position line.
+func (m *Model) layout() {
+	if m.height == 0 || m.width == 0 {
+		return
+	}
+	rows := m.body(m.frame()) - 4
+	if m.tab == tabStats {
+		m.statsView.SetSize(m.width-2, rows)
+		return
+	}
+	lw, dw := m.paneWidths()
+	if lw > 0 {
+		m.list.SetSize(lw, rows)
+	}
+	if dw > 0 {
+		m.detail.SetSize(dw, rows)
+	}
+}
+
+func (m Model) pane(title, content string, width int, focused bool) string {
+	st := m.st.Pane
+	mark := "  "
+	if focused {
+		st, mark = m.st.Focused, "▶ "
+	}
+	if width > 0 {
+		st = st.Width(width)
+		if h := m.body(m.frame()) - 2; h > 0 {
+			st = st.Height(h)
+		}
+	}
+	return st.Render(m.st.Title.Render(mark+title) + "\n" + strings.TrimSuffix(content, "\n"))
+}
+
+func (m Model) View() string {
+	f := m.frame()
+	if m.tab == tabStats {
+		w := 0
+		if m.width > 0 {
+			w = m.width - 2
+		}
+		return lipgloss.JoinVertical(lipgloss.Left,
+			f.header, f.status, f.bar,
+			m.pane("통계", m.statsView.View(), w, true),
+			f.help,
+		)
+	}
+
+	list := m.list.View()
+	if m.list.Len() == 0 {
+		list = "(아직 경고 없음)\n"
+	}
+	detail := "(선택된 경고 없음)\n"
+	if _, ok := m.list.SelectedAlert(); ok {
+		detail = m.detail.View()
+	}
+
+	lw, dw := m.paneWidths()
+	var panes []string
+	if lw > 0 || m.width == 0 {
+		panes = append(panes, m.pane("경고 목록", list, lw, m.focus == focusList))
+	}
+	if dw > 0 || (m.width == 0 && m.focus == focusDetail) {
+		panes = append(panes, m.pane("상세 (tab/enter 포커스, ↑↓ 스크롤)", detail, dw, m.focus == focusDetail))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		f.header, f.status, f.bar,
+		lipgloss.JoinHorizontal(lipgloss.Top, panes...),
+		f.help,
+	)
+}
@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Pager shows a long text (the alert detail) a screenful at a time.
+type Pager struct {
+	lines         []string
+	width, height int // 0 = unknown: show everything
+	offset        int
+	styles        Styles
+}
+
+// SetContent replaces the text and scrolls back to the top.
+func (p *Pager) SetContent(s string) {
+	p.lines = strings.Split(strings.TrimRight(s, "\n"), "\n")
+	p.offset = 0
+}
+
+// Refresh replaces the text but keeps the scroll position, for a view
+// that is redrawn while being read (the stats tab).
+func (p *Pager) Refresh(s string) {
+	p.lines = strings.Split(strings.TrimRight(s, "\n"), "\n")
+	p.clamp()
+}
+
+func (p *Pager) SetStyles(s Styles) { p.styles = s }
+
+func (p *Pager) SetSize(width, height int) {
+	p.width, p.height = width, max(height, 1)
+	p.clamp()
+}
+
+// HandleKey scrolls with ↑/k ↓/j pgup pgdown g G and reports whether k
+// was used.
+func (p *Pager) HandleKey(k tea.KeyMsg) bool {
+	switch k.String() {
+	case "up", "k":
+		p.offset--
+	case "down", "j":
+		p.offset++
+	case "pgup", "b":
+		p.offset -= p.page()
+	case "pgdown", "f", " ":
+		p.offset += p.page()
+	case "g", "home":
+		p.offset = 0
+	case "G", "end":
+		p.offset = len(p.lines)
+	default:
+		return false
+	}
+	p.clamp()
+	return true
+}
+
+func (p *Pager) page() int { return max(p.height-1, 1) }
+
+func (p *Pager) clamp() {
+	p.offset = max(0, min(p.offset, len(p.lines)-p.visible()))
+}
+
+func (p *Pager) visible() int {
+	if p.height == 0 {
+		return len(p.lines)
+	}
+	return min(p.height, len(p.lines))
+}
+
+// View renders the visible lines with a scrollbar, then a position line.
+func (p *Pager) View() string {
+	n := p.visible()
+	var b strings.Builder
+	p.styles.writeLines(&b, p.lines[p.offset:p.offset+n], p.width, p.styles.scrollbar(len(p.lines), p.offset, n))
+	b.WriteString(p.styles.position(p.offset, n, len(p.lines), "줄"))
+	return b.String()
+}
+
+// scrollGlyphs are the scrollbar track and thumb, the position line
+// rule and the cut-off mark; plain styles stay in ASCII.
+var (
+	scrollGlyphs      = [4]string{"│", "┃", "──", "…"}
+	scrollGlyphsPlain = [4]string{"|", "#", "--", "..."}
+)
+
+func (st Styles) glyphs() (track, thumb, rule, tail string) {
+	g := scrollGlyphsPlain
+	if st.Color {
+		g = scrollGlyphs
+	}
+	return g[0], g[1], g[2], g[3]
+}
+
+// scrollbar returns the gutter character for each of the n visible
+// lines of a total-line text scrolled to offset, or nil when it all fits.
+func (st Styles) scrollbar(total, offset, n int) []string {
+	if n <= 0 || total <= n {
+		return nil
+	}
+	size := max(n*n/total, 1)
+	top := offset * n / total
+	if offset+n >= total {
+		top = n - size // 끝까지 내렸으면 썸도 맨 아래
+	}
+	track, thumb, _, _ := st.glyphs()
+	bar := make([]string, n)
+	for i := range bar {
+		bar[i] = track
+		if i >= top && i < top+size {
+			bar[i] = thumb
+		}
+	}
+	return bar
+}
+
+// writeLines writes lines cut to width, with the scrollbar in the last
+// column when there is one.
+func (st Styles) writeLines(b *strings.Builder, lines []string, width int, bar []string) {
+	_, _, _, tail := st.glyphs()
+	for i, line := range lines {
+		if width > 0 {
+			w := width
+			if bar != nil {
+				w--
+			}
+			line = ansi.Truncate(line, w, tail)
+			if bar != nil {
+				line += strings.Repeat(" ", max(w-ansi.StringWidth(line), 0)) + bar[i]
+			}
+		} else if bar != nil {
+			line += " " + bar[i]
+		}
+		b.WriteString(line + "\n")
+	}
+}
+
+// position is the "a-b / n" indicator under a scrolled view.
+func (st Styles) position(offset, n, total int, unit string) string {
+	if total == 0 {
+		return ""
+	}
+	_, _, rule, _ := st.glyphs()
+	return fmt.Sprintf("%s %d-%d / %d%s %s\n", rule, offset+1, offset+n, total, unit, rule)
+}